@@ -0,0 +1,56 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+)
+
+// getAnnotations returns the key/value annotations of a command, like
+// "stability: beta", which are collected through the optional
+// `interface{ CommandAnnotations() map[string]string }` method or set with the
+// AnnotateGroup function for the command groups.
+func getAnnotations(c Command) map[string]string {
+	if cg, ok := c.(*cmdGroup); ok {
+		return cg.annotations
+	}
+	if v, ok := c.(interface{ CommandAnnotations() map[string]string }); ok {
+		return v.CommandAnnotations()
+	}
+	return nil
+}
+
+// sortedKeys returns the annotation keys in the sorted order.
+func sortedKeys(annotations map[string]string) []string {
+	var keys []string
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AnnotateGroup adds the key/value annotations to a command group created by
+// the Group function, which are included in the spec and the generated docs
+// for the tools that tag the commands, like with the owner team names. Other
+// commands declare their annotations through the optional
+// `interface{ CommandAnnotations() map[string]string }` method. It panics if
+// the command is not a command group.
+func AnnotateGroup(group Command, annotations map[string]string) Command {
+	cg, ok := group.(*cmdGroup)
+	if !ok {
+		panic(fmt.Sprintf("command %q is not a command group", getName(group)))
+	}
+	if cg.annotations == nil {
+		cg.annotations = make(map[string]string)
+	}
+	maps.Copy(cg.annotations, annotations)
+	return cg
+}
+
+// Annotations returns the key/value annotations of the command.
+func (n *Node) Annotations() map[string]string {
+	return getAnnotations(unwrapHidden(n.Cmd))
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// NoArgs wraps the main function of a command that takes no positional
+// arguments, so that stray arguments, which are often typos, are reported as
+// errors instead of being silently ignored.
+func NoArgs(mainf MainFunc) MainFunc {
+	return func(ctx context.Context, args []string) error {
+		if len(args) > 0 {
+			return withCommandPath(ctx, errors.New(msg(MsgUnexpectedArgument, args[0])))
+		}
+		return mainf(ctx, args)
+	}
+}
+
+// ArgsValidator checks the positional arguments of a command.
+type ArgsValidator func(args []string) error
+
+// ValidateArgs adds the validators to a command group created by the Group
+// function, which check the positional arguments of all commands under the
+// group before they are run, so that the validation common to the sibling
+// commands, like the job id argument of all "job" subcommands, is defined in
+// one place. Validators of the outer groups run first. It panics if the
+// command is not a command group.
+func ValidateArgs(group Command, validators ...ArgsValidator) Command {
+	cg, ok := group.(*cmdGroup)
+	if !ok {
+		panic(fmt.Sprintf("command %q is not a command group", getName(group)))
+	}
+	cg.validators = append(cg.validators, validators...)
+	return cg
+}
+
+// ArgMatches returns a validator that checks the positional argument at the
+// index is present and matches the regular expression.
+func ArgMatches(index int, re *regexp.Regexp) ArgsValidator {
+	return func(args []string) error {
+		if index >= len(args) {
+			return errors.New(msg(MsgMissingArgument, index+1))
+		}
+		if !re.MatchString(args[index]) {
+			return errors.New(msg(MsgArgumentMismatch, args[index], re.String()))
+		}
+		return nil
+	}
+}
+
+// validateArgs runs the validators of all command groups in the command path
+// for the arguments.
+func validateArgs(cmdpath []*cmdData, args []string) error {
+	for _, c := range cmdpath {
+		cg, ok := c.cmd.(*cmdGroup)
+		if !ok {
+			continue
+		}
+		for _, validate := range cg.validators {
+			if err := validate(args); err != nil {
+				return fmt.Errorf("%s: %w", strings.Join(getPath(cmdpath), " "), err)
+			}
+		}
+	}
+	return nil
+}
+
+// withCommandPath prefixes the error message with the command path saved in
+// the context, when available.
+func withCommandPath(ctx context.Context, err error) error {
+	if path := getCommandPath(ctx); len(path) > 0 {
+		return fmt.Errorf("%s: %w", strings.Join(path, " "), err)
+	}
+	return err
+}
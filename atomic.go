@@ -0,0 +1,100 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFileAtomic writes a file through the `write` callback such that the
+// file at `path` either has the old contents or the complete new contents,
+// even when the command is interrupted. New contents are written to a
+// temporary file in the same directory which is renamed over the target file
+// only when the callback succeeds and the context is not canceled.
+func WriteFileAtomic(ctx context.Context, path string, perm os.FileMode, write func(w io.Writer) error) error {
+	return writeFileAtomic(ctx, path, perm, false, write)
+}
+
+// WriteFileAtomicWithBackup is like WriteFileAtomic, but retains the previous
+// contents of the file, if any, with a ".bak" suffix.
+func WriteFileAtomicWithBackup(ctx context.Context, path string, perm os.FileMode, write func(w io.Writer) error) error {
+	return writeFileAtomic(ctx, path, perm, true, write)
+}
+
+func writeFileAtomic(ctx context.Context, path string, perm os.FileMode, backup bool, write func(w io.Writer) error) (status error) {
+	dir, base := filepath.Split(path)
+	if len(dir) == 0 {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if status != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := write(tmp); err != nil {
+		return err
+	}
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	if backup {
+		if err := backupFile(path, path+".bak"); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// backupFile makes a copy of the file, if it exists, replacing any existing
+// backup. A hard link is used when possible.
+func backupFile(path, bak string) error {
+	if err := os.Remove(bak); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(path, bak); err == nil || errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	fi, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
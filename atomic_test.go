@@ -0,0 +1,49 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "data.txt")
+
+	writeString := func(s string) func(io.Writer) error {
+		return func(w io.Writer) error {
+			_, err := io.WriteString(w, s)
+			return err
+		}
+	}
+
+	if err := WriteFileAtomic(ctx, file, 0o600, writeString("one")); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomicWithBackup(ctx, file, 0o600, writeString("two")); err != nil {
+		t.Fatal(err)
+	}
+
+	failure := errors.New("failure")
+	if err := WriteFileAtomic(ctx, file, 0o600, func(w io.Writer) error {
+		io.WriteString(w, "partial")
+		return failure
+	}); !errors.Is(err, failure) {
+		t.Fatalf("want %v, got %v", failure, err)
+	}
+
+	if data, err := os.ReadFile(file); err != nil || string(data) != "two" {
+		t.Fatalf("want `two`, got %q (%v)", data, err)
+	}
+	if data, err := os.ReadFile(file + ".bak"); err != nil || string(data) != "one" {
+		t.Fatalf("want `one` in backup, got %q (%v)", data, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(file)); len(entries) != 2 {
+		t.Fatalf("want no temporary files, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+type benchCmd struct {
+	count    int
+	duration time.Duration
+}
+
+// Bench creates a "bench" command that runs another command from the same
+// command tree multiple times, with it's standard output discarded, and
+// reports the latency percentiles. Output written directly to `os.Stdout`,
+// instead of the cmdctx.Stdout writer, is not discarded.
+//
+//	$ mytool bench -count 100 db get mykey
+func Bench() Command {
+	return new(benchCmd)
+}
+
+func (c *benchCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet("bench", flag.ContinueOnError)
+	fset.IntVar(&c.count, "count", 10, "number of times to run the command")
+	fset.DurationVar(&c.duration, "duration", 0, "when non-zero, runs the command repeatedly for the duration")
+	return fset, c.run
+}
+
+func (c *benchCmd) CommandHelp() string {
+	return `Runs a command repeatedly and reports it's latency percentiles.
+
+Command and it's arguments are given as the arguments to the bench command.
+Standard output of the command is discarded. When -duration flag is set, it
+takes precedence over the -count flag.
+`
+}
+
+func (c *benchCmd) run(ctx context.Context, args []string) error {
+	root, ok := rootKey.Value(ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", msg(MsgNeedsRun, "bench"), os.ErrInvalid)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("%s: %w", msg(MsgBenchNeedsCommand), os.ErrInvalid)
+	}
+
+	// Nested runs parse the arguments into the same flags, so flag values are
+	// restored before every run to keep the runs independent.
+	restore := root.saveFlagValues()
+	defer restore()
+
+	// discarded output has no terminal capabilities
+	ctx = WithTerminal(ctx, new(Terminal))
+
+	var latencies []time.Duration
+	runOnce := func() error {
+		restore()
+		next := root.rerun()
+		next.opts.stdout = io.Discard
+		start := time.Now()
+		if err := next.run(ctx, args); err != nil {
+			return err
+		}
+		latencies = append(latencies, time.Since(start))
+		return nil
+	}
+
+	count, duration := c.count, c.duration
+
+	deadline := time.Now().Add(duration)
+	for i := 0; ; i++ {
+		if duration > 0 && !time.Now().Before(deadline) {
+			break
+		}
+		if duration <= 0 && i >= count {
+			break
+		}
+		if err := context.Cause(ctx); err != nil {
+			return err
+		}
+		if err := runOnce(); err != nil {
+			return fmt.Errorf("%s: %w", msg(MsgBenchRunFailed, i+1), err)
+		}
+	}
+
+	printLatencies(getStdout(ctx), latencies)
+	return nil
+}
+
+func printLatencies(w io.Writer, latencies []time.Duration) {
+	if len(latencies) == 0 {
+		fmt.Fprintln(w, msg(MsgBenchNoRuns))
+		return
+	}
+	slices.Sort(latencies)
+
+	var total time.Duration
+	for _, d := range latencies {
+		total += d
+	}
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+
+	fmt.Fprintln(w, msg(MsgBenchRuns, len(latencies)))
+	fmt.Fprintln(w, msg(MsgBenchMean, total/time.Duration(len(latencies))))
+	fmt.Fprintln(w, msg(MsgBenchMin, latencies[0]))
+	fmt.Fprintln(w, msg(MsgBenchP50, percentile(50)))
+	fmt.Fprintln(w, msg(MsgBenchP90, percentile(90)))
+	fmt.Fprintln(w, msg(MsgBenchP99, percentile(99)))
+	fmt.Fprintln(w, msg(MsgBenchMax, latencies[len(latencies)-1]))
+}
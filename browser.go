@@ -0,0 +1,57 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+func withNoBrowser(ctx context.Context, disabled bool) context.Context {
+	if disabled {
+		return cmdctx.WithNoBrowser(ctx, true)
+	}
+	return ctx
+}
+
+// OpenURL opens the URL in the user's web browser, which is useful for login
+// flows and commands that open dashboards. URL is printed for the user to open
+// instead, when the session is not interactive, when there is no browser or
+// when the builtin -no-browser flag is given.
+func OpenURL(ctx context.Context, url string) error {
+	if !cmdctx.NoBrowser(ctx) && isTerminalWriter(getStdout(ctx)) {
+		if args := browserCommand(url); args != nil {
+			cmd := exec.Command(args[0], args[1:]...)
+			if err := cmd.Start(); err == nil {
+				// browser launchers exit quickly, but must not become zombies
+				go cmd.Wait()
+				return nil
+			}
+		}
+	}
+	_, err := fmt.Fprintln(getStderr(ctx), msg(MsgOpenURL, url))
+	return err
+}
+
+// browserCommand returns the command to open the URL in the web browser for
+// the current platform, which is nil when no browser is available.
+func browserCommand(url string) []string {
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{"open", url}
+	case "windows":
+		return []string{"rundll32", "url.dll,FileProtocolHandler", url}
+	}
+	if len(os.Getenv("DISPLAY")) == 0 && len(os.Getenv("WAYLAND_DISPLAY")) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath("xdg-open"); err != nil {
+		return nil
+	}
+	return []string{"xdg-open", url}
+}
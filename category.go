@@ -0,0 +1,33 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+// getCategory returns the category of a core command, which is collected
+// through the optional `interface{ CommandCategory() string }` method.
+// Categories of the plugin provided commands are ignored, because they are
+// grouped by the plugin names instead.
+func getCategory(c Command) string {
+	if len(getOrigin(c)) > 0 {
+		return ""
+	}
+	if v, ok := c.(interface{ CommandCategory() string }); ok {
+		return v.CommandCategory()
+	}
+	return ""
+}
+
+// HelpCategory describes the subcommands of a category in the help output.
+type HelpCategory struct {
+	Name     string
+	Commands []HelpCommand
+}
+
+// getCategoryCommands returns the core subcommands that declare a category,
+// grouped by the category names, in the sorted order.
+func getCategoryCommands(cmdpath []*cmdData) []HelpCategory {
+	var categories []HelpCategory
+	for _, g := range groupSubcommands(cmdpath, getCategory) {
+		categories = append(categories, HelpCategory{Name: g.name, Commands: g.cmds})
+	}
+	return categories
+}
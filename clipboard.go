@@ -0,0 +1,72 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+func withCopy(ctx context.Context, enabled bool) context.Context {
+	if enabled {
+		return cmdctx.WithCopy(ctx, true)
+	}
+	return ctx
+}
+
+// PrintValue prints the primary output value of a command, like a generated
+// token or an id, on the standard output. When the builtin -copy flag is
+// given, the value is also copied to the system clipboard, or a message is
+// printed to the standard error when no clipboard is available.
+func PrintValue(ctx context.Context, value string) {
+	fmt.Fprintln(getStdout(ctx), value)
+	if !cmdctx.Copy(ctx) {
+		return
+	}
+	if err := copyToClipboard(ctx, value); err != nil {
+		fmt.Fprintln(getStderr(ctx), msg(MsgClipboardFailed))
+		return
+	}
+	fmt.Fprintln(getStderr(ctx), msg(MsgClipboardCopied))
+}
+
+// clipboardCommands returns the candidate clipboard programs for the current
+// platform in the order of preference.
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+	var cmds [][]string
+	if len(os.Getenv("WAYLAND_DISPLAY")) > 0 {
+		cmds = append(cmds, []string{"wl-copy"})
+	}
+	if len(os.Getenv("DISPLAY")) > 0 {
+		cmds = append(cmds, []string{"xclip", "-selection", "clipboard"}, []string{"xsel", "--clipboard", "--input"})
+	}
+	return cmds
+}
+
+// copyToClipboard copies the text to the system clipboard with the first
+// available clipboard program.
+func copyToClipboard(ctx context.Context, text string) error {
+	for _, args := range clipboardCommands() {
+		path, err := exec.LookPath(args[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.CommandContext(ctx, path, args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+// Command subcmd-new creates a new subcommand source file, along with a test
+// file, following the conventions of the subcmd package.
+//
+// # EXAMPLE
+//
+//	$ subcmd-new -package jobs -name pause -dir ./jobs
+//
+// It can also be used from a go:generate directive:
+//
+//	//go:generate go run github.com/bvkgo/subcmd/cmd/subcmd-new -name pause
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+type params struct {
+	Package  string
+	Name     string
+	TypeName string
+	TestName string
+	Synopsis string
+}
+
+// Help returns the Go string literal for the CommandHelp method, which is a
+// raw string unless the synopsis has back-quotes.
+func (p *params) Help() string {
+	help := p.Synopsis + "\n\nTODO: Describe the command in detail.\n"
+	if strings.Contains(help, "`") {
+		return strconv.Quote(help)
+	}
+	return "`" + help + "`"
+}
+
+var cmdTemplate = template.Must(template.New("cmd").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"flag"
+
+	"github.com/bvkgo/subcmd"
+)
+
+type {{.TypeName}} struct {
+	// TODO: Add fields for the command-line flags.
+}
+
+// run implements the ` + "`main`" + ` method for "{{.Name}}" subcommand.
+func (c *{{.TypeName}}) run(ctx context.Context, args []string) error {
+	// TODO: Implement the command.
+	return nil
+}
+
+// Command implements the subcmd.Command interface.
+func (c *{{.TypeName}}) Command() (*flag.FlagSet, subcmd.MainFunc) {
+	fset := flag.NewFlagSet({{printf "%q" .Name}}, flag.ContinueOnError)
+	// TODO: Define the command-line flags.
+	return fset, subcmd.MainFunc(c.run)
+}
+
+// CommandHelp implements the optional help interface for subcommands.
+func (c *{{.TypeName}}) CommandHelp() string {
+	return {{.Help}}
+}
+`))
+
+var testTemplate = template.Must(template.New("test").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bvkgo/subcmd"
+	"github.com/bvkgo/subcmd/subcmdtest"
+)
+
+func Test{{.TestName}}(t *testing.T) {
+	ctx := context.Background()
+
+	subcmdtest.Conformance(t, new({{.TypeName}}))
+
+	cmds := []subcmd.Command{new({{.TypeName}})}
+	if err := subcmd.Run(ctx, cmds, []string{ {{- printf "%q" .Name -}} }); err != nil {
+		t.Fatal(err)
+	}
+}
+`))
+
+// typeName converts a command name like "pause-all" into a type name like
+// "pauseAllCmd".
+func typeName(name string) string {
+	var sb strings.Builder
+	upper := false
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = sb.Len() > 0
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		if sb.Len() == 0 {
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String() + "Cmd"
+}
+
+// testName converts a command name like "pause-all" into a test function
+// name suffix like "PauseAllCmd".
+func testName(name string) string {
+	tname := []rune(typeName(name))
+	tname[0] = unicode.ToUpper(tname[0])
+	return string(tname)
+}
+
+// namePattern matches the valid subcommand names, like "pause-all".
+var namePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_-]*$`)
+
+// check returns an error if the parameters cannot be used in the generated
+// Go source.
+func (p *params) check() error {
+	if len(p.Name) == 0 {
+		return errors.New("subcommand name cannot be empty")
+	}
+	if !namePattern.MatchString(p.Name) {
+		return fmt.Errorf("subcommand name %q must start with a letter and have only letters, digits, '-' or '_'", p.Name)
+	}
+	if len(p.Package) == 0 {
+		return errors.New("package name cannot be empty")
+	}
+	if !token.IsIdentifier(p.Package) {
+		return fmt.Errorf("package name %q is not a Go identifier", p.Package)
+	}
+	if strings.ContainsAny(p.Synopsis, "\r\n") {
+		return errors.New("synopsis must be a single line")
+	}
+	return nil
+}
+
+// render executes the template and returns the formatted Go source.
+func render(t *template.Template, p *params) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// generate writes the sources into the files, keyed by the file names.
+// Existing files are never overwritten and either all files are created or
+// none of them are.
+func generate(files map[string][]byte) (status error) {
+	var created []string
+	defer func() {
+		if status != nil {
+			for _, file := range created {
+				os.Remove(file)
+			}
+		}
+	}()
+
+	names := make([]string, 0, len(files))
+	for file := range files {
+		if _, err := os.Lstat(file); err == nil {
+			return fmt.Errorf("file %q already exists: %w", file, os.ErrExist)
+		}
+		names = append(names, file)
+	}
+	sort.Strings(names)
+	for _, file := range names {
+		f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return err
+		}
+		created = append(created, file)
+		if _, err := f.Write(files[file]); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	var (
+		name     = flag.String("name", "", "name of the new subcommand")
+		pkg      = flag.String("package", os.Getenv("GOPACKAGE"), "package name for the generated files")
+		dir      = flag.String("dir", ".", "directory for the generated files")
+		synopsis = flag.String("synopsis", "", "one line description for the subcommand")
+		noTest   = flag.Bool("no-test", false, "when true, test file is not generated")
+	)
+	flag.Parse()
+
+	if len(*synopsis) == 0 {
+		*synopsis = "TODO: Describe the command in one line."
+	}
+	p := &params{
+		Package:  *pkg,
+		Name:     *name,
+		Synopsis: *synopsis,
+	}
+	if err := newCommand(p, *dir, !*noTest); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newCommand generates the source file, and the test file when requested, for
+// a new subcommand into the directory.
+func newCommand(p *params, dir string, withTest bool) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	p.TypeName, p.TestName = typeName(p.Name), testName(p.Name)
+
+	base := filepath.Join(dir, strings.ReplaceAll(p.Name, "-", "_"))
+	files := make(map[string][]byte)
+	src, err := render(cmdTemplate, p)
+	if err != nil {
+		return err
+	}
+	files[base+".go"] = src
+	if withTest {
+		src, err := render(testTemplate, p)
+		if err != nil {
+			return err
+		}
+		files[base+"_test.go"] = src
+	}
+	return generate(files)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package main
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTypeName(t *testing.T) {
+	for name, want := range map[string]string{
+		"pause":     "pauseCmd",
+		"pause-all": "pauseAllCmd",
+		"Get_Key":   "getKeyCmd",
+	} {
+		if got := typeName(name); got != want {
+			t.Errorf("typeName(%q): want %q, got %q", name, want, got)
+		}
+	}
+}
+
+func TestNewCommand(t *testing.T) {
+	dir := t.TempDir()
+
+	p := &params{Package: "jobs", Name: "pause-all", Synopsis: "Pauses the `running` jobs \"now\"."}
+	if err := newCommand(p, dir, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"pause_all.go", "pause_all_test.go"} {
+		if _, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, file), nil, 0); err != nil {
+			t.Fatalf("want valid Go source in %s, got %v", file, err)
+		}
+	}
+
+	// existing test file must prevent creating the source file too
+	if err := os.WriteFile(filepath.Join(dir, "resume_test.go"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p = &params{Package: "jobs", Name: "resume", Synopsis: "Resumes the jobs."}
+	if err := newCommand(p, dir, true); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("want ErrExist for an existing file, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "resume.go")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want no source file after the failure, got %v", err)
+	}
+
+	for _, p := range []*params{
+		{Package: "jobs", Name: `pause"all`, Synopsis: "Pauses."},
+		{Package: "jobs", Name: "pause", Synopsis: "Pauses\nthe jobs."},
+		{Package: "my-jobs", Name: "pause", Synopsis: "Pauses."},
+	} {
+		if err := newCommand(p, dir, false); err == nil {
+			t.Fatalf("want error for invalid parameters %+v", p)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+// Package cmdctx defines the typed context keys for the values that the subcmd
+// package saves in the context of a command invocation, like the input and
+// output streams, the resolved command path, the flags of the command, the
+// terminal capabilities and the builtin switches.
+//
+// Commands and the programs that embed a command tree read these values with
+// the getters in this package instead of relying on the subcmd internals.
+// Programs can also define their own keys with the NewKey function, which
+// never collide with the framework keys or with other packages' keys.
+package cmdctx
+
+import (
+	"context"
+	"flag"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// Key is a typed key for a context value. Keys are compared by their
+// identity, so two keys never collide even when they have the same name.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a new key for the context values of type T. Name is used
+// only for debugging.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// String returns the key name.
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+// With returns a copy of the context that holds the value for the key.
+func (k *Key[T]) With(ctx context.Context, v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+// Value returns the value for the key from the context and true, or the zero
+// value and false when the context has no value for the key.
+func (k *Key[T]) Value(ctx context.Context) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+var (
+	stdinKey   = NewKey[io.Reader]("stdin")
+	stdoutKey  = NewKey[io.Writer]("stdout")
+	stderrKey  = NewKey[io.Writer]("stderr")
+	pathKey    = NewKey[[]string]("path")
+	flagsKey   = NewKey[*flag.FlagSet]("flags")
+	randKey    = NewKey[*rand.Rand]("rand")
+	offlineKey = NewKey[bool]("offline")
+	copyKey    = NewKey[bool]("copy")
+	browserKey = NewKey[bool]("no-browser")
+	termKey    = NewKey[*Terminal]("terminal")
+)
+
+// WithOutput returns a copy of the context with the writers for the standard
+// output and the standard error of the command. A nil writer keeps the value
+// from the parent context.
+func WithOutput(ctx context.Context, stdout, stderr io.Writer) context.Context {
+	if stdout != nil {
+		ctx = stdoutKey.With(ctx, stdout)
+	}
+	if stderr != nil {
+		ctx = stderrKey.With(ctx, stderr)
+	}
+	return ctx
+}
+
+// Stdout returns the writer for the command output, which is selected with
+// the subcmd.WithOutput option. It returns `os.Stdout` by default.
+func Stdout(ctx context.Context) io.Writer {
+	if w, ok := stdoutKey.Value(ctx); ok {
+		return w
+	}
+	return os.Stdout
+}
+
+// Stderr returns the writer for the command diagnostics, which is selected
+// with the subcmd.WithOutput option. It returns `os.Stderr` by default.
+func Stderr(ctx context.Context) io.Writer {
+	if w, ok := stderrKey.Value(ctx); ok {
+		return w
+	}
+	return os.Stderr
+}
+
+// WithInput returns a copy of the context with the reader for the standard
+// input of the command.
+func WithInput(ctx context.Context, stdin io.Reader) context.Context {
+	return stdinKey.With(ctx, stdin)
+}
+
+// Stdin returns the reader for the command input, which is the file selected
+// with the -input flag defined by the subcmd.AddRedirectFlags function. It
+// returns `os.Stdin` by default.
+func Stdin(ctx context.Context) io.Reader {
+	if r, ok := stdinKey.Value(ctx); ok {
+		return r
+	}
+	return os.Stdin
+}
+
+// WithPath returns a copy of the context with the resolved command path.
+func WithPath(ctx context.Context, path []string) context.Context {
+	return pathKey.With(ctx, path)
+}
+
+// Path returns the resolved command path, starting with the program name, or
+// nil when the context is not from a command invocation.
+func Path(ctx context.Context) []string {
+	v, _ := pathKey.Value(ctx)
+	return v
+}
+
+// WithFlags returns a copy of the context with the flags of the command.
+func WithFlags(ctx context.Context, fset *flag.FlagSet) context.Context {
+	return flagsKey.With(ctx, fset)
+}
+
+// Flags returns the flags of the running command, which hold the values
+// parsed from the command-line, or nil when the context is not from a command
+// invocation.
+func Flags(ctx context.Context) *flag.FlagSet {
+	v, _ := flagsKey.Value(ctx)
+	return v
+}
+
+// WithRand returns a copy of the context with the random number generator.
+func WithRand(ctx context.Context, r *rand.Rand) context.Context {
+	return randKey.With(ctx, r)
+}
+
+// Rand returns the per-invocation random number generator, which is seeded
+// from the builtin -seed flag when given. It returns a new generator with a
+// time based seed when the context has none. Returned object is not safe for
+// concurrent use.
+func Rand(ctx context.Context) *rand.Rand {
+	if r, ok := randKey.Value(ctx); ok {
+		return r
+	}
+	return rand.New(rand.NewSource(time.Now().UnixNano()))
+}
+
+// WithOffline returns a copy of the context with the offline mode.
+func WithOffline(ctx context.Context, offline bool) context.Context {
+	return offlineKey.With(ctx, offline)
+}
+
+// Offline returns true when the command is run in the offline mode, which is
+// selected with the builtin -offline flag.
+func Offline(ctx context.Context) bool {
+	v, _ := offlineKey.Value(ctx)
+	return v
+}
+
+// WithCopy returns a copy of the context with the clipboard mode.
+func WithCopy(ctx context.Context, enabled bool) context.Context {
+	return copyKey.With(ctx, enabled)
+}
+
+// Copy returns true when the primary output value of the command must also be
+// copied to the clipboard, which is selected with the builtin -copy flag.
+func Copy(ctx context.Context) bool {
+	v, _ := copyKey.Value(ctx)
+	return v
+}
+
+// WithNoBrowser returns a copy of the context with the browser mode.
+func WithNoBrowser(ctx context.Context, disabled bool) context.Context {
+	return browserKey.With(ctx, disabled)
+}
+
+// NoBrowser returns true when the URLs must be printed instead of opening them
+// in a web browser, which is selected with the builtin -no-browser flag.
+func NoBrowser(ctx context.Context) bool {
+	v, _ := browserKey.Value(ctx)
+	return v
+}
+
+// Terminal describes the output capabilities of the terminal connected to the
+// standard output.
+type Terminal struct {
+	// IsTTY is true when the standard output is a terminal.
+	IsTTY bool
+
+	// Colors is the number of colors supported by the terminal, which is zero
+	// when colors are not supported or disabled with NO_COLOR environment
+	// variable.
+	Colors int
+
+	// Unicode is true when the terminal locale supports UTF-8.
+	Unicode bool
+
+	// Width and Height are the terminal dimensions in characters, which are
+	// zero when unknown.
+	Width, Height int
+}
+
+// WithTerminal returns a copy of the context with the terminal capabilities.
+func WithTerminal(ctx context.Context, t *Terminal) context.Context {
+	return termKey.With(ctx, t)
+}
+
+// TerminalInfo returns the terminal capabilities probed for the command
+// invocation, or nil when the context has none.
+func TerminalInfo(ctx context.Context) *Terminal {
+	v, _ := termKey.Value(ctx)
+	return v
+}
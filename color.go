@@ -0,0 +1,95 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"text/template"
+)
+
+// colorMode is the flag.Value for the builtin -color flag.
+type colorMode string
+
+func (v *colorMode) String() string {
+	if len(*v) == 0 {
+		return "auto"
+	}
+	return string(*v)
+}
+
+func (v *colorMode) Set(s string) error {
+	switch s {
+	case "auto", "always", "never":
+		*v = colorMode(s)
+		return nil
+	}
+	return errors.New(msg(MsgInvalidColorMode))
+}
+
+// ANSI escape sequences used for the help output and the warnings.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiCyan   = "\x1b[36m"
+	ansiYellow = "\x1b[33m"
+)
+
+// helpStyle decorates the help output elements with ANSI colors when colors
+// are enabled, and leaves them unchanged otherwise. Help text is wrapped to
+// the width when it is non-zero.
+type helpStyle struct {
+	color bool
+	width int
+}
+
+// getHelpStyle returns the help style for the -color flag value and the
+// terminal width. Colors are used in the auto mode only when the standard
+// output is a color terminal and the NO_COLOR environment variable is not
+// set.
+func (cg *cmdGroup) getHelpStyle(ctx context.Context) helpStyle {
+	term := TerminalInfo(ctx)
+	style := helpStyle{color: term.Colors > 0, width: term.Width}
+	switch cg.color {
+	case "always":
+		style.color = true
+	case "never":
+		style.color = false
+	}
+	if style.width == 0 {
+		style.width = cg.opts.wrapWidth
+	}
+	return style
+}
+
+func (s helpStyle) paint(code, text string) string {
+	if !s.color || len(text) == 0 {
+		return text
+	}
+	return code + text + ansiReset
+}
+
+// header decorates the section headers, like "Usage:" and "Flags:".
+func (s helpStyle) header(text string) string { return s.paint(ansiBold, text) }
+
+// command decorates the command names.
+func (s helpStyle) command(text string) string { return s.paint(ansiCyan, text) }
+
+// flag decorates the flag names.
+func (s helpStyle) flag(text string) string { return s.paint(ansiYellow, text) }
+
+// funcs returns the template functions to decorate the help template output
+// and to format the localized messages.
+func (s helpStyle) funcs() template.FuncMap {
+	return template.FuncMap{
+		"header":  s.header,
+		"command": s.command,
+		"flag":    s.flag,
+		"msg": func(id string, args ...any) string {
+			return msg(MessageID(id), args...)
+		},
+		"msgn": func(id string, count int, args ...any) string {
+			return msgn(MessageID(id), count, args...)
+		},
+	}
+}
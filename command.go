@@ -8,10 +8,97 @@
 // Commands can define flags using `flag.FlagSet` objects from the Go standard
 // library. Commands can also provide detailed documentation which is optional.
 //
-// A few special top-level commands "help", "flags", and "commands" are added
-// automatically for documentation. More detailed documentation is collected
-// through the optional `interface{ CommandHelp() string }` method on the
-// Command objects.
+// A few special top-level commands "help", "flags", "commands" and "which" are
+// added automatically for documentation, where `help -all` (or the
+// `-help-all` flag) prints the help for the entire command tree in one pass,
+// including the flags marked with FlagInfo.Advanced, `help <topic>` prints
+// the documentation pages registered with the RegisterHelpTopic function and
+// `which <args>` prints the command that would run for the arguments and where
+// it is defined.
+// Top-level commands with the same names replace the special commands, which
+// can also be disabled with the WithBuiltins option. More detailed
+// documentation is collected through the optional
+// `interface{ CommandHelp() string }` method on the Command objects, where the
+// first sentence is used as the synopsis. Commands can instead provide the
+// synopsis and the long description separately through the optional
+// `interface{ CommandSynopsis() string }` and
+// `interface{ CommandDescription() string }` methods. Example invocations for
+// a command are listed in the help and generated docs through the optional
+// `interface{ CommandExamples() []Example }` method. Related commands are
+// listed in a "see also" section through the optional
+// `interface{ CommandSeeAlso() []string }` method, which returns the command
+// paths without the program name, like "db get".
+//
+// Usage lines show the positional argument placeholders returned by the
+// optional `interface{ CommandUsage() string }` method, like "<key> <value>",
+// instead of the generic "<args>".
+//
+// Commands wrapped with the `Hidden` function can be run, but are not listed
+// in the help and documentation outputs.
+//
+// Subcommands of a group can be listed in separate help sections by declaring
+// a category, like "Debugging", through the optional
+// `interface{ CommandCategory() string }` method.
+//
+// Commands provided by plugins can report the plugin name through the optional
+// `interface{ CommandOrigin() string }` method, so that they are listed
+// separately from the core commands in the help and the spec exports.
+//
+// Commands can be marked as deprecated with a message through the optional
+// `interface{ CommandDeprecated() string }` method and flags with the
+// FlagInfo.Deprecated field, which are marked in the help output and print a
+// warning when they are used.
+//
+// Commands can be tagged with key/value annotations, like "stability: beta",
+// through the optional `interface{ CommandAnnotations() map[string]string }`
+// method or with the `AnnotateGroup` function for the command groups, which
+// are included in the spec exports and the generated docs.
+//
+// Help output is rendered with a `text/template` template, which can be
+// replaced for all commands with the `SetHelpTemplate` function or for a single
+// command through the optional `interface{ CommandHelpTemplate() string }`
+// method. A special `-color=auto|always|never` flag controls the colors in
+// the help output, which are used in the auto mode only when the standard
+// output is a color terminal and the NO_COLOR environment variable is not set.
+//
+// Commands can tag errors with stable error codes using the `Coded` function,
+// which are documented with `RegisterErrorCode` and displayed by the builtin
+// "help errors" command.
+//
+// Commands can also declare soft resource budgets for memory, CPUs and run time
+// through the optional `interface{ CommandLimits() Limits }` method.
+//
+// Paragraphs of the help text can be marked with a minimum tool version by
+// starting them with a line like `[since v1.2]`, so that documentation
+// generated for older versions omits them.
+//
+// A special `-print-command` flag is also recognized at all levels, which
+// prints the resolved command path, effective flag values and the residual
+// arguments instead of running the command. Flag values that implement
+// `interface{ IsSecret() bool }` are redacted from this output, as in the
+// values returned by the SnapshotFlags function.
+//
+// Similarly, a special `-seed` flag takes an integer seed for the random number
+// generator returned by the `Rand` function, so that commands that use random
+// numbers can be made reproducible, a special `-debug-resolve` flag prints the
+// command resolution steps to the standard error, and a special `-warnings-as-errors` flag
+// makes the command fail when it reported any warnings with the `Warn`
+// function. A special `-offline` flag selects the offline mode, which is
+// honored by the framework subsystems that need network access and can be
+// checked by the commands with the `Offline` function. A special `-copy` flag
+// copies the value printed with the `PrintValue` function to the clipboard and
+// a special `-no-browser` flag makes the `OpenURL` function print the URLs
+// instead of opening them.
+//
+// Commands can opt into the standard `-input file` and `-output-file file`
+// flags with the `AddRedirectFlags` function, in which case the framework
+// opens the input file and writes the command output to the output file
+// atomically.
+//
+// Values saved in the context for a command invocation, like the input and
+// output streams, the command path, the flags of the running command, the
+// terminal capabilities and the builtin switches, can be read with the typed
+// getters from the `cmdctx` package.
 //
 // # EXAMPLE 1
 //
@@ -34,6 +121,9 @@
 //
 //	// Run implements the `main` method for "run" subcommand.
 //	func (r *runCmd) Run(ctx context.Context, args []string) error {
+//		if r.background {
+//			return subcmd.Detach(ctx, "background", "/var/run/daemon.pid")
+//		}
 //		...
 //		if len(p.dataDir) == 0 {
 //			p.dataDir = filepath.Join(os.Getenv("HOME"), ".data")
@@ -69,8 +159,9 @@ type Command interface {
 	// flags. Returned `flag.FlagSet` must have a non-empty name which is taken
 	// as the subcommand name.
 	//
-	// NOTE: This method is called just once per subcommand, so implementations
-	// can return a new `flag.FlagSet` object.
+	// NOTE: This method is called just once per subcommand instance, so
+	// implementations can return a new `flag.FlagSet` object. Results are
+	// memoized for every Run function call with a NodeCache.
 	Command() (*flag.FlagSet, MainFunc)
 }
 
@@ -87,14 +178,26 @@ func Group(name, description string, cmds ...Command) Command {
 // Run parses command-line arguments from `args` into flags and subcommands and
 // selects the most appropriate subcommand to execute from `cmds`. Global
 // command-line flags from `flag.CommandLine` are also processed on the way to
-// resolving the final subcommand.
-func Run(ctx context.Context, cmds []Command, args []string) error {
+// resolving the final subcommand. Parsing and other behavior can be customized
+// with the options.
+func Run(ctx context.Context, cmds []Command, args []string, opts ...Option) error {
 	if cmds == nil {
 		return os.ErrInvalid
 	}
 	root := cmdGroup{
 		flags:   flag.CommandLine,
 		subcmds: cmds,
+		nodes:   new(NodeCache),
+	}
+	for _, opt := range opts {
+		opt(&root.opts)
+	}
+	if root.opts.rewriter != nil {
+		v, err := root.opts.rewriter(args)
+		if err != nil {
+			return err
+		}
+		args = v
 	}
 	return root.run(ctx, args)
 }
@@ -0,0 +1,64 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+// rootKey holds the root command group of the invocation.
+var rootKey = cmdctx.NewKey[*cmdGroup]("root")
+
+// seedValue is the flag.Value for the builtin -seed flag.
+type seedValue struct {
+	set   bool
+	value int64
+}
+
+func (v *seedValue) String() string {
+	return strconv.FormatInt(v.value, 10)
+}
+
+func (v *seedValue) Set(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	v.set, v.value = true, n
+	return nil
+}
+
+func withRoot(ctx context.Context, root *cmdGroup) context.Context {
+	return rootKey.With(ctx, root)
+}
+
+// withPath saves the resolved command path, starting with the program name,
+// in the context.
+func withPath(ctx context.Context, path []string) context.Context {
+	return cmdctx.WithPath(ctx, path)
+}
+
+// getCommandPath returns the resolved command path saved in the context.
+func getCommandPath(ctx context.Context) []string {
+	return cmdctx.Path(ctx)
+}
+
+func withRand(ctx context.Context, seed *seedValue) context.Context {
+	if !seed.set {
+		seed.value = time.Now().UnixNano()
+	}
+	return cmdctx.WithRand(ctx, rand.New(rand.NewSource(seed.value)))
+}
+
+// Rand returns the per-invocation random number generator for the commands. It
+// is seeded from the `-seed` command-line flag when given, so that commands
+// generating random ids or sample data can be reproduced for bug reports and
+// tests. Returned object is not safe for concurrent use.
+func Rand(ctx context.Context) *rand.Rand {
+	return cmdctx.Rand(ctx)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"strings"
+)
+
+// getDeprecated returns the deprecation message of a command, which is
+// collected through the optional `interface{ CommandDeprecated() string }`
+// method, like "use the backup create command instead". It is empty when the
+// command is not deprecated.
+func getDeprecated(c Command) string {
+	if v, ok := c.(interface{ CommandDeprecated() string }); ok {
+		return strings.TrimSpace(v.CommandDeprecated())
+	}
+	return ""
+}
+
+// getListedSynopsis returns the synopsis for the subcommand listings, which
+// is marked for the deprecated commands.
+func getListedSynopsis(c Command) string {
+	synopsis := getSynopsis(c)
+	if len(getDeprecated(c)) > 0 {
+		return strings.TrimSpace(msg(MsgDeprecatedMarker) + " " + synopsis)
+	}
+	return synopsis
+}
+
+// warnDeprecated prints warnings for the deprecated command and the deprecated
+// flags used on the command-line.
+func warnDeprecated(ctx context.Context, path []string, c Command, flags []*flag.Flag) {
+	if m := getDeprecated(c); len(m) > 0 {
+		Warn(ctx, "%s", msg(MsgCommandDeprecated, strings.Join(path, " "), m))
+	}
+	for _, f := range flags {
+		Warn(ctx, "%s", msg(MsgFlagDeprecated, f.Name, getFlagInfo(f).Deprecated))
+	}
+}
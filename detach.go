@@ -0,0 +1,137 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Detach re-executes the current invocation as a daemonized background process
+// with the same command-line arguments except for the flag named `flagName`
+// and writes the process id into the `pidFile`. It is meant to be called from
+// a command's main function when it's detach or background flag is set.
+//
+// First line of the pid file holds the process id and the remaining lines hold
+// the quoted command-line arguments for the background process. It fails with
+// os.ErrExist when the pid file refers to a running process and prints the
+// command to check the status of the background process, which is the
+// command created by the StatusCommand function when it is in the tree.
+func Detach(ctx context.Context, flagName, pidFile string) error {
+	return startDetached(ctx, removeFlag(os.Args[1:], flagName), pidFile)
+}
+
+func startDetached(ctx context.Context, args []string, pidFile string) error {
+	if pid, _, err := readPidFile(pidFile); err == nil && isRunning(pid) {
+		return fmt.Errorf("%s: %w", msg(MsgAlreadyRunning, pid, pidFile), os.ErrExist)
+	}
+
+	binary, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	null, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer null.Close()
+
+	cmd := exec.Command(binary, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = null, null, null
+	cmd.SysProcAttr = detachedAttr()
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", msg(MsgStartFailed), err)
+	}
+	pid := cmd.Process.Pid
+	if err := cmd.Process.Release(); err != nil {
+		return err
+	}
+
+	if err := writePidFile(ctx, pidFile, pid, args); err != nil {
+		return fmt.Errorf("%s: %w", msg(MsgPidFileWriteFailed, pid), err)
+	}
+	fmt.Fprintln(getStdout(ctx), msg(MsgStarted, pid, pidFile))
+	if status := statusCommand(ctx, pidFile, pid); len(status) > 0 {
+		fmt.Fprintln(getStdout(ctx), msg(MsgCheckStatus, status))
+	}
+	return nil
+}
+
+// statusCommand returns the command-line to check the status of the background
+// process, which runs the status command for the pid file from the command
+// tree when there is one, or a platform specific command otherwise.
+func statusCommand(ctx context.Context, pidFile string, pid int) string {
+	var status []string
+	if root, ok := rootKey.Value(ctx); ok && len(getCommandPath(ctx)) > 0 {
+		prog := getCommandPath(ctx)[0]
+		walkTree(root.nodes, nil, root.subcmds, func(path []string, c Command) bool {
+			if sc, ok := c.(*superviseCmd); ok && status == nil && sc.name == "status" && sc.pidFile == pidFile {
+				status = append([]string{prog}, path...)
+			}
+			return true
+		})
+	}
+	if status != nil {
+		return strings.Join(status, " ")
+	}
+	return statusHint(pid)
+}
+
+// removeFlag removes all occurrences of a boolean flag from the command-line
+// arguments before the "--" separator.
+func removeFlag(args []string, name string) []string {
+	var result []string
+	for i, s := range args {
+		if s == "--" {
+			result = append(result, args[i:]...)
+			break
+		}
+		if len(s) > 1 && s[0] == '-' {
+			flag, _, _ := strings.Cut(strings.TrimPrefix(s[1:], "-"), "=")
+			if flag == name {
+				continue
+			}
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func writePidFile(ctx context.Context, pidFile string, pid int, args []string) error {
+	return WriteFileAtomic(ctx, pidFile, 0o644, func(w io.Writer) error {
+		fmt.Fprintf(w, "%d\n", pid)
+		for _, arg := range args {
+			if _, err := fmt.Fprintf(w, "%s\n", strconv.Quote(arg)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func readPidFile(pidFile string) (int, []string, error) {
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		return 0, nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w", msg(MsgPidFileInvalidPid, pidFile), err)
+	}
+	var args []string
+	for _, line := range lines[1:] {
+		arg, err := strconv.Unquote(line)
+		if err != nil {
+			return 0, nil, fmt.Errorf("%s: %w", msg(MsgPidFileInvalidArg, pidFile), err)
+		}
+		args = append(args, arg)
+	}
+	return pid, args, nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || windows)
+
+package subcmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachedAttr returns nil because process sessions are not supported on
+// this platform.
+func detachedAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// isRunning returns false because process existence cannot be checked on this
+// platform.
+func isRunning(pid int) bool {
+	return false
+}
+
+// terminate returns os.ErrInvalid because processes cannot be signaled on
+// this platform.
+func terminate(pid int) error {
+	return os.ErrInvalid
+}
+
+// statusHint returns empty because processes cannot be checked on this
+// platform.
+func statusHint(pid int) string {
+	return ""
+}
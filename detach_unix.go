@@ -0,0 +1,40 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package subcmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// detachedAttr returns the process attributes to run a process in a new
+// session without a controlling terminal.
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// isRunning returns true if a process with the pid exists.
+func isRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
+
+// terminate requests the process to exit gracefully.
+func terminate(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Signal(syscall.SIGTERM)
+}
+
+// statusHint returns a shell command that checks if the process is running.
+func statusHint(pid int) string {
+	return fmt.Sprintf("kill -0 %d", pid)
+}
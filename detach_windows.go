@@ -0,0 +1,47 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+//go:build windows
+
+package subcmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// detachedProcess is the DETACHED_PROCESS process creation flag.
+const detachedProcess = 0x00000008
+
+// detachedAttr returns the process attributes to run a process without a
+// console in a new process group.
+func detachedAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess,
+	}
+}
+
+// isRunning returns true if a process with the pid exists.
+func isRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
+
+// terminate kills the process because detached processes cannot receive
+// console control events on windows.
+func terminate(pid int) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
+
+// statusHint returns a command that lists the process when it is running.
+func statusHint(pid int) string {
+	return fmt.Sprintf(`tasklist /FI "PID eq %d"`, pid)
+}
@@ -0,0 +1,296 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// walkTree calls the visitor for every command in the tree in the depth-first
+// order with the command names from the root as the path. Commands are
+// visited in the sorted order of their names. Subcommands of a group are
+// skipped when the visitor returns false for the group.
+func walkTree(nodes *NodeCache, path []string, cmds []Command, visitor func([]string, Command) bool) {
+	sorted := slices.Clone(cmds)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return nodes.name(sorted[i]) < nodes.name(sorted[j])
+	})
+	for _, c := range sorted {
+		cpath := append(slices.Clip(path), nodes.name(c))
+		if !visitor(cpath, c) {
+			continue
+		}
+		if cg, ok := c.(*cmdGroup); ok {
+			walkTree(nodes, cpath, cg.subcmds, visitor)
+		}
+	}
+}
+
+// walkCommands is like walkTree, but skips the hidden commands.
+func walkCommands(nodes *NodeCache, path []string, cmds []Command, visitor func([]string, Command)) {
+	walkTree(nodes, path, cmds, func(cpath []string, c Command) bool {
+		if isHidden(c) {
+			return false
+		}
+		visitor(cpath, c)
+		return true
+	})
+}
+
+// filterHelp removes the help text paragraphs that are marked with a minimum
+// tool version newer than the `version` parameter. A paragraph is marked by a
+// first line of the form `[since v1.2]`, which is itself never displayed. When
+// version is empty all paragraphs are kept.
+func filterHelp(text, version string) string {
+	if !strings.Contains(text, "[since ") {
+		return text
+	}
+	var paras []string
+	for _, para := range strings.Split(text, "\n\n") {
+		first, rest, _ := strings.Cut(strings.TrimLeft(para, "\n"), "\n")
+		first = strings.TrimSpace(first)
+		if !strings.HasPrefix(first, "[since ") || !strings.HasSuffix(first, "]") {
+			paras = append(paras, para)
+			continue
+		}
+		since := strings.TrimSpace(first[len("[since ") : len(first)-1])
+		if len(version) == 0 || compareVersions(since, version) <= 0 {
+			paras = append(paras, rest)
+		}
+	}
+	return strings.Join(paras, "\n\n")
+}
+
+// compareVersions compares two dotted version strings with an optional "v"
+// prefix numerically and returns -1, 0 or +1. Non-numeric components are
+// compared as strings.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for len(as) < len(bs) {
+		as = append(as, "0")
+	}
+	for len(bs) < len(as) {
+		bs = append(bs, "0")
+	}
+	for i := range as {
+		x, xerr := strconv.Atoi(as[i])
+		y, yerr := strconv.Atoi(bs[i])
+		if xerr != nil || yerr != nil {
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// DocFormat identifies the markup language for the generated docs.
+type DocFormat string
+
+// Markup languages supported for the generated docs.
+const (
+	FormatMarkdown DocFormat = "markdown"
+	FormatAsciiDoc DocFormat = "asciidoc"
+	FormatReST     DocFormat = "rst"
+)
+
+// WriteUsageMarkdown writes a Markdown formatted "Usage" section, suitable for
+// a README file, with all commands in the tree and their synopses, followed by
+// the examples of the commands. Program name is taken from the `name`
+// parameter.
+func WriteUsageMarkdown(w io.Writer, name string, cmds []Command) error {
+	return WriteUsage(w, name, "", FormatMarkdown, cmds)
+}
+
+// WriteVersionedUsageMarkdown is like WriteUsageMarkdown, but omits the help
+// text sections marked with a tool version newer than the `version` parameter,
+// so that docs for older release branches can be generated.
+func WriteVersionedUsageMarkdown(w io.Writer, name, version string, cmds []Command) error {
+	return WriteUsage(w, name, version, FormatMarkdown, cmds)
+}
+
+// WriteUsage is like WriteVersionedUsageMarkdown, but writes the "Usage"
+// section in the given markup language, so that it can be included in the
+// AsciiDoc (Antora) or reStructuredText (Sphinx) documentation. All help text
+// sections are kept when version is empty.
+func WriteUsage(w io.Writer, name, version string, format DocFormat, cmds []Command) error {
+	return writeUsage(w, new(NodeCache), name, version, format, cmds)
+}
+
+func writeUsage(w io.Writer, nodes *NodeCache, name, version string, format DocFormat, cmds []Command) error {
+	// only the commands referenced from the "see also" links get anchors
+	targets := make(map[string]bool)
+	walkCommands(nodes, nil, cmds, func(path []string, c Command) {
+		for _, p := range getSeeAlso(c) {
+			targets[p] = true
+		}
+	})
+
+	var buf bytes.Buffer
+	switch format {
+	case FormatMarkdown:
+		fmt.Fprintf(&buf, "## Usage\n\n")
+		fmt.Fprintf(&buf, "```\n%s <subcommand> <args>\n```\n\n", name)
+		fmt.Fprintf(&buf, "| Command | Description |\n")
+		fmt.Fprintf(&buf, "|---------|-------------|\n")
+	case FormatAsciiDoc:
+		fmt.Fprintf(&buf, "== Usage\n\n")
+		fmt.Fprintf(&buf, "----\n%s <subcommand> <args>\n----\n\n", name)
+		fmt.Fprintf(&buf, "[cols=\"1,3\",options=\"header\"]\n|===\n")
+		fmt.Fprintf(&buf, "|Command |Description\n\n")
+	case FormatReST:
+		fmt.Fprintf(&buf, "Usage\n=====\n\n")
+		fmt.Fprintf(&buf, "::\n\n   %s <subcommand> <args>\n\n", name)
+		fmt.Fprintf(&buf, ".. list-table::\n   :header-rows: 1\n\n")
+		fmt.Fprintf(&buf, "   * - Command\n     - Description\n")
+	default:
+		return fmt.Errorf("%s: %w", msg(MsgDocFormatInvalid, format), os.ErrInvalid)
+	}
+
+	walkCommands(nodes, nil, cmds, func(path []string, c Command) {
+		cmdpath := strings.Join(path, " ")
+		synopsis := getVersionedSynopsis(c, version)
+		related := getSeeAlso(c)
+		switch format {
+		case FormatMarkdown:
+			synopsis = strings.ReplaceAll(synopsis, "|", `\|`)
+			var links []string
+			for _, p := range related {
+				links = append(links, fmt.Sprintf("[`%s %s`](#%s)", name, p, anchorName(p)))
+			}
+			if len(links) > 0 {
+				synopsis = fmt.Sprintf("%s See also %s.", synopsis, strings.Join(links, ", "))
+			}
+			anchor := ""
+			if targets[cmdpath] {
+				anchor = fmt.Sprintf(`<a id="%s"></a>`, anchorName(cmdpath))
+			}
+			fmt.Fprintf(&buf, "| %s`%s %s` | %s |\n", anchor, name, cmdpath, synopsis)
+		case FormatAsciiDoc:
+			synopsis = strings.ReplaceAll(synopsis, "|", `\|`)
+			var links []string
+			for _, p := range related {
+				links = append(links, fmt.Sprintf("<<%s,`%s %s`>>", anchorName(p), name, p))
+			}
+			if len(links) > 0 {
+				synopsis = fmt.Sprintf("%s See also %s.", synopsis, strings.Join(links, ", "))
+			}
+			anchor := ""
+			if targets[cmdpath] {
+				anchor = fmt.Sprintf("[[%s]]", anchorName(cmdpath))
+			}
+			fmt.Fprintf(&buf, "|%s`%s %s` |%s\n", anchor, name, cmdpath, synopsis)
+		case FormatReST:
+			var links []string
+			for _, p := range related {
+				links = append(links, fmt.Sprintf("`%s %s`_", name, p))
+			}
+			if len(links) > 0 {
+				synopsis = fmt.Sprintf("%s See also %s.", synopsis, strings.Join(links, ", "))
+			}
+			command := fmt.Sprintf("``%s %s``", name, cmdpath)
+			if targets[cmdpath] {
+				command = fmt.Sprintf("_`%s %s`", name, cmdpath)
+			}
+			fmt.Fprintf(&buf, "   * - %s\n     - %s\n", command, synopsis)
+		}
+	})
+	if format == FormatAsciiDoc {
+		fmt.Fprintf(&buf, "|===\n")
+	}
+	writeUsageExamples(&buf, nodes, name, format, cmds)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeUsageExamples writes an "Examples" subsection of the usage section with
+// the examples of every command that has them.
+func writeUsageExamples(w io.Writer, nodes *NodeCache, name string, format DocFormat, cmds []Command) {
+	first := true
+	walkCommands(nodes, nil, cmds, func(path []string, c Command) {
+		examples := getExamples(c)
+		if len(examples) == 0 {
+			return
+		}
+		command := fmt.Sprintf("%s %s", name, strings.Join(path, " "))
+		switch format {
+		case FormatMarkdown:
+			if first {
+				fmt.Fprintf(w, "\n### Examples\n")
+			}
+			fmt.Fprintf(w, "\n#### `%s`\n", command)
+		case FormatAsciiDoc:
+			if first {
+				fmt.Fprintf(w, "\n=== Examples\n")
+			}
+			fmt.Fprintf(w, "\n==== `%s`\n", command)
+		case FormatReST:
+			if first {
+				fmt.Fprintf(w, "\nExamples\n--------\n")
+			}
+			title := fmt.Sprintf("``%s``", command)
+			fmt.Fprintf(w, "\n%s\n%s\n", title, strings.Repeat("~", len(title)))
+		}
+		first = false
+
+		for _, e := range examples {
+			if len(e.Description) > 0 {
+				fmt.Fprintf(w, "\n%s\n", e.Description)
+			}
+			switch format {
+			case FormatMarkdown:
+				fmt.Fprintf(w, "\n```\n$ %s\n```\n", e.Command)
+			case FormatAsciiDoc:
+				fmt.Fprintf(w, "\n----\n$ %s\n----\n", e.Command)
+			case FormatReST:
+				fmt.Fprintf(w, "\n::\n\n   $ %s\n", e.Command)
+			}
+		}
+	})
+}
+
+type usageCmd struct {
+	format  string
+	version string
+}
+
+func (c *usageCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet("usage", flag.ContinueOnError)
+	fset.StringVar(&c.format, "format", string(FormatMarkdown), "markup language (markdown, asciidoc or rst)")
+	fset.StringVar(&c.version, "version", "", "omits help sections of newer versions")
+	return fset, c.run
+}
+
+func (c *usageCmd) CommandHelp() string {
+	return `Prints the usage section for the documentation.
+
+Prints a "Usage" section with all commands, their synopses and examples in
+Markdown, AsciiDoc or reStructuredText markup, which can be included in the
+README files or documentation sites.
+`
+}
+
+func (c *usageCmd) run(ctx context.Context, args []string) error {
+	root, ok := rootKey.Value(ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", msg(MsgNeedsRun, "docs usage"), os.ErrInvalid)
+	}
+	return writeUsage(getStdout(ctx), root.nodes, getName(root), c.version, DocFormat(c.format), root.subcmds)
+}
@@ -0,0 +1,227 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFilterHelp(t *testing.T) {
+	help := `Scans the database.
+
+[since v1.2]
+Supports the -limit flag.
+
+[since v1.10]
+Supports the -reverse flag.
+`
+	for _, test := range []struct {
+		version string
+		want    string
+	}{
+		{"", "Scans the database.\n\nSupports the -limit flag.\n\nSupports the -reverse flag.\n"},
+		{"v1.1", "Scans the database."},
+		{"v1.2", "Scans the database.\n\nSupports the -limit flag."},
+		{"1.10.1", "Scans the database.\n\nSupports the -limit flag.\n\nSupports the -reverse flag.\n"},
+	} {
+		if got := filterHelp(help, test.version); got != test.want {
+			t.Fatalf("version %q: want %q, got %q", test.version, test.want, got)
+		}
+	}
+}
+
+func TestSpecDiff(t *testing.T) {
+	scan := newTestCmd("scan")
+	scan.flags.Int("limit", 10, "max number of items")
+	scan.flags.Bool("all", false, "scan all tables")
+	from := NewSpec("tool", []Command{Group("db", "Database commands.", scan, newTestCmd("get"))})
+
+	scan = newTestCmd("scan")
+	scan.flags.Int("limit", 20, "max number of items")
+	scan.flags.String("all", "", "tables to scan")
+	scan.flags.String("prefix", "", "key prefix")
+	to := NewSpec("tool", []Command{Group("db", "Database commands.", scan, newTestCmd("put"))})
+
+	var sb strings.Builder
+	if err := WriteSpecDiff(&sb, from, to); err != nil {
+		t.Fatal(err)
+	}
+	want := "## Command-line changes\n\n" +
+		"- Removed command `db get`\n" +
+		"- Added command `db put`\n" +
+		"- Changed type of flag `-all` of `db scan` from bool to string\n" +
+		"- Changed default of flag `-limit` of `db scan` from \"10\" to \"20\"\n" +
+		"- Added flag `-prefix` to `db scan`\n"
+	if sb.String() != want {
+		t.Fatalf("want %q, got %q", want, sb.String())
+	}
+
+	sb.Reset()
+	if err := WriteSpecDiff(&sb, to, to); err != nil || sb.Len() != 0 {
+		t.Fatalf("want no changes, got %q", sb.String())
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	scan := newTestCmd("scan")
+	scan.flags.Int("limit", 10, "max number of items")
+	baseline := NewSpec("tool", []Command{scan})
+
+	scan = newTestCmd("scan")
+	scan.flags.Int("limit", 10, "max number of items")
+	scan.flags.Bool("all", false, "scan all tables")
+	if err := CheckCompatibility(baseline, []Command{scan, newTestCmd("get")}); err != nil {
+		t.Fatalf("want added command and flag to be compatible, got %v", err)
+	}
+
+	scan = newTestCmd("scan")
+	scan.flags.Int("limit", 20, "max number of items")
+	err := CheckCompatibility(baseline, []Command{scan})
+	if cerr := new(CompatError); !errors.As(err, &cerr) || len(cerr.Changes) != 1 {
+		t.Fatalf("want one incompatible change, got %v", err)
+	}
+	if err := CheckCompatibility(baseline, nil); err == nil {
+		t.Fatalf("want removed command to be incompatible")
+	}
+}
+
+type seeAlsoCmd struct {
+	TestCmd
+	related []string
+}
+
+func (s *seeAlsoCmd) CommandSeeAlso() []string {
+	return s.related
+}
+
+func TestSeeAlso(t *testing.T) {
+	scan := &seeAlsoCmd{TestCmd: *newTestCmd("scan"), related: []string{"db get"}}
+	cmds := []Command{Group("db", "Database commands.", scan, newTestCmd("get"))}
+	if err := Validate(cmds); err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := WriteUsageMarkdown(&sb, "tool", cmds); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"| <a id=\"db-get\"></a>`tool db get` |",
+		"See also [`tool db get`](#db-get). |",
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Fatalf("want %q in the markdown, got %q", want, sb.String())
+		}
+	}
+
+	scan.related = []string{"db put"}
+	if err := Validate(cmds); err == nil {
+		t.Fatalf("want error for undefined related command")
+	}
+}
+
+func TestWriteUsage(t *testing.T) {
+	scan := &seeAlsoCmd{TestCmd: *newTestCmd("scan"), related: []string{"db get"}}
+	cmds := []Command{Group("db", "Database commands.", scan, newTestCmd("get"))}
+	for _, test := range []struct {
+		format DocFormat
+		want   []string
+	}{
+		{FormatAsciiDoc, []string{"|[[db-get]]`tool db get` |", "See also <<db-get,`tool db get`>>.\n", "|===\n"}},
+		{FormatReST, []string{"   * - _`tool db get`\n", "See also `tool db get`_.\n", "   * - ``tool db``\n     - Database commands.\n"}},
+	} {
+		var sb strings.Builder
+		if err := WriteUsage(&sb, "tool", "", test.format, cmds); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range test.want {
+			if !strings.Contains(sb.String(), want) {
+				t.Errorf("%s: want %q in the output, got %q", test.format, want, sb.String())
+			}
+		}
+	}
+
+	show := &exampleCmd{TestCmd: *newTestCmd("show")}
+	for format, want := range map[DocFormat]string{
+		FormatMarkdown: "\n### Examples\n\n#### `tool show`\n\nShow the first item.\n\n```\n$ tool show 1\n```\n\n```\n$ tool show -all\n```\n",
+		FormatAsciiDoc: "|===\n\n=== Examples\n\n==== `tool show`\n\nShow the first item.\n\n----\n$ tool show 1\n----\n",
+		FormatReST:     "\nExamples\n--------\n\n``tool show``\n~~~~~~~~~~~~~\n\nShow the first item.\n\n::\n\n   $ tool show 1\n",
+	} {
+		var sb strings.Builder
+		if err := WriteUsage(&sb, "tool", "", format, []Command{show}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("%s: want examples %q in the output, got %q", format, want, sb.String())
+		}
+	}
+
+	if err := WriteUsage(io.Discard, "tool", "", "html", cmds); err == nil {
+		t.Fatalf("want error for unsupported format")
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	scan := &seeAlsoCmd{TestCmd: *newTestCmd("scan"), related: []string{"db get"}}
+	cmds := []Command{Group("db", "Database <commands>.", scan, newTestCmd("get"))}
+
+	var sb strings.Builder
+	if err := WriteHTML(&sb, cmds); err != nil {
+		t.Fatal(err)
+	}
+	prog := getPath([]*cmdData{newDocsRoot(nil, cmds)})[0]
+	for _, want := range []string{
+		fmt.Sprintf(`<section id="%s-db-get">`, prog),
+		fmt.Sprintf(`<a href="#%s-db-get"><code>%s db get</code></a>`, prog, prog),
+		"Database &lt;commands&gt;.",
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("want %q in the output, got %q", want, sb.String())
+		}
+	}
+
+	dir := t.TempDir()
+	if err := GenHTMLPages(cmds, dir); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, prog+"-db-scan.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf(`<a href="%s-db.html">db</a>`, prog); !strings.Contains(string(data), want) {
+		t.Errorf("want %q in the page, got %q", want, data)
+	}
+}
+
+type annotatedCmd struct {
+	TestCmd
+}
+
+func (c *annotatedCmd) CommandAnnotations() map[string]string {
+	return map[string]string{"stability": "beta"}
+}
+
+func TestAnnotations(t *testing.T) {
+	scan := &annotatedCmd{TestCmd: *newTestCmd("scan")}
+	db := AnnotateGroup(Group("db", "Database commands.", scan), map[string]string{"owner": "infra-team"})
+
+	spec := NewSpec("tool", []Command{db})
+	var got []string
+	for _, c := range spec.Commands {
+		for _, k := range sortedKeys(c.Annotations) {
+			got = append(got, c.Path+":"+k+"="+c.Annotations[k])
+		}
+	}
+	if want := "db:owner=infra-team,db scan:stability=beta"; strings.Join(got, ",") != want {
+		t.Fatalf("want annotations %q, got %q", want, strings.Join(got, ","))
+	}
+	if new(NodeCache).NodeOf(scan).Annotations()["stability"] != "beta" {
+		t.Fatalf("want annotations from the node")
+	}
+}
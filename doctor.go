@@ -0,0 +1,76 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Severity defines how a failing health check is reported by the doctor
+// command.
+type Severity int
+
+const (
+	// SeverityFail reports the failing check as a failure and makes the doctor
+	// command return an error.
+	SeverityFail Severity = iota
+
+	// SeverityWarn reports the failing check as a warning.
+	SeverityWarn
+)
+
+// HealthCheck defines a check run by the doctor command.
+type HealthCheck struct {
+	// Name is a short description of the check.
+	Name string
+
+	// Severity is used when the check fails.
+	Severity Severity
+
+	// FixHint describes how to fix the problem when the check fails.
+	FixHint string
+
+	// Check runs the check and returns non-nil error on failure.
+	Check func(ctx context.Context) error
+}
+
+// Doctor creates a "doctor" command that runs all the health checks in the
+// order and prints a pass/warn/fail report. It returns an error if any of the
+// checks with SeverityFail have failed.
+func Doctor(checks ...HealthCheck) Command {
+	mainf := func(ctx context.Context, args []string) error {
+		return runChecks(ctx, getStdout(ctx), checks)
+	}
+	return New("doctor", "Runs health checks and reports problems.", mainf)
+}
+
+func runChecks(ctx context.Context, w io.Writer, checks []HealthCheck) error {
+	var npass, nwarn, nfail int
+	for _, c := range checks {
+		err := c.Check(ctx)
+		if err == nil {
+			npass++
+			fmt.Fprintln(w, msg(MsgCheckPassed, c.Name))
+			continue
+		}
+		if c.Severity == SeverityWarn {
+			nwarn++
+			fmt.Fprintln(w, msg(MsgCheckWarned, c.Name, err))
+		} else {
+			nfail++
+			fmt.Fprintln(w, msg(MsgCheckFailed, c.Name, err))
+		}
+		if len(c.FixHint) > 0 {
+			fmt.Fprintln(w, msg(MsgCheckHint, c.FixHint))
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, msgn(MsgChecksSummary, nwarn, npass, nwarn, nfail))
+	if nfail > 0 {
+		return errors.New(msgn(MsgChecksFailed, nfail, nfail))
+	}
+	return nil
+}
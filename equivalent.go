@@ -0,0 +1,45 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// EchoEquivalent prints the scriptable form of the current command, with the
+// flag values that differ from the defaults and the arguments, to the
+// standard error as a comment line like `# equivalent: mytool db set -ttl=5m
+// key value`. Commands should call it after interactive prompts fill in the
+// values, so that users can learn the non-interactive form. Secret flag values
+// are redacted. Echo can be disabled with the WithoutEquivalentEcho option.
+func EchoEquivalent(ctx context.Context, fset *flag.FlagSet, args ...string) {
+	if root, ok := rootKey.Value(ctx); ok && root.opts.noEcho {
+		return
+	}
+	fmt.Fprintf(getStderr(ctx), "# %s %s\n", msg(MsgEquivalentPrefix), equivalentCommand(ctx, fset, args))
+}
+
+// equivalentCommand returns the command line for the current command with the
+// flags from the flag set that differ from their defaults.
+func equivalentCommand(ctx context.Context, fset *flag.FlagSet, args []string) string {
+	words := getCommandPath(ctx)
+	values := SnapshotFlags(fset)
+	for _, f := range listFlags(fset) {
+		if f.Value.String() == f.DefValue {
+			continue
+		}
+		value := values[f.Name]
+		if fv, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && fv.IsBoolFlag() && value == "true" {
+			words = append(words, "-"+f.Name)
+			continue
+		}
+		words = append(words, "-"+f.Name+"="+shellQuote(value))
+	}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ")
+}
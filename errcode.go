@@ -0,0 +1,75 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	errorCodesMu sync.Mutex
+	errorCodes   = make(map[string]string)
+)
+
+// RegisterErrorCode adds an error code and it's documentation to the error
+// catalog, which is displayed by the builtin "help errors" command. First line
+// of the documentation is used as the summary in the catalog listing.
+func RegisterErrorCode(code, help string) {
+	errorCodesMu.Lock()
+	defer errorCodesMu.Unlock()
+	errorCodes[code] = help
+}
+
+// Coded tags the error with a stable error code. When a command fails with a
+// coded error, the Run function prints a hint to look up the error code in the
+// error catalog. It returns nil if err is nil.
+func Coded(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// printErrorHint prints a hint to look up the error code for coded errors.
+func printErrorHint(w io.Writer, prog string, err error) {
+	var cerr *CodedError
+	if errors.As(err, &cerr) {
+		fmt.Fprintln(w, msg(MsgSeeErrorCode, prog, cerr.Code))
+	}
+}
+
+// printErrorCodes prints the documentation for the error codes in args or
+// lists all registered error codes with their summaries when args is empty.
+func printErrorCodes(w io.Writer, args []string) error {
+	errorCodesMu.Lock()
+	defer errorCodesMu.Unlock()
+
+	if len(args) == 0 {
+		var codes []string
+		for code := range errorCodes {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "\t%-15s  %s\n", code, getFirstLine(errorCodes[code]))
+		}
+		return nil
+	}
+
+	for i, code := range args {
+		help, ok := errorCodes[code]
+		if !ok {
+			return errors.New(msg(MsgErrorCodeNotDefined, code))
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\n\n%s\n", code, strings.TrimSpace(help))
+	}
+	return nil
+}
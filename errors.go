@@ -0,0 +1,77 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import "strings"
+
+// ParseError is the error type returned when command-line arguments cannot be
+// resolved into a subcommand and it's flags. Wrappers can use the position
+// information to point the user at the offending argument.
+type ParseError struct {
+	// Index is the position of the offending token in the argument list.
+	Index int
+
+	// Token is the offending command-line argument.
+	Token string
+
+	// Path holds the command names resolved before the failure, starting with
+	// the program name.
+	Path []string
+
+	// Err is the underlying parse error.
+	Err error
+}
+
+// Error returns the parse error message prefixed with the resolved command
+// path, so that the failing level is clear in the deep command trees.
+func (e *ParseError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Err.Error()
+	}
+	return strings.Join(e.Path, " ") + ": " + e.Err.Error()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// CycleError is the error type returned when moved command stubs forward to
+// each other in a loop.
+type CycleError struct {
+	// Chain holds the command paths in the forwarding order, with the first
+	// repeated path at the end.
+	Chain []string
+}
+
+func (e *CycleError) Error() string {
+	return msg(MsgCommandCycle, strings.Join(e.Chain, " -> "))
+}
+
+// CodedError is the error type returned by the Coded function to tag errors
+// with a stable error code, which is documented in the error catalog.
+type CodedError struct {
+	// Code is the stable error code, like E1042.
+	Code string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *CodedError) Error() string {
+	return e.Code + ": " + e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// CompatError is the error type returned by the CheckCompatibility function
+// when a command tree has incompatible changes relative to a baseline spec.
+type CompatError struct {
+	// Changes describes the incompatible changes.
+	Changes []string
+}
+
+func (e *CompatError) Error() string {
+	return msgn(MsgIncompatibleChanges, len(e.Changes), len(e.Changes)) + ":\n\t" + strings.Join(e.Changes, "\n\t")
+}
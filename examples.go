@@ -0,0 +1,23 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+// Example describes an example invocation of a command, which is listed in the
+// examples section of the help and documentation outputs.
+type Example struct {
+	// Description explains what the example does.
+	Description string
+
+	// Command is the full command line for the example, like
+	// `mytool db scan -limit 10`.
+	Command string
+}
+
+// getExamples returns the examples for a command, which are collected through
+// the optional `interface{ CommandExamples() []Example }` method.
+func getExamples(c Command) []Example {
+	if v, ok := c.(interface{ CommandExamples() []Example }); ok {
+		return v.CommandExamples()
+	}
+	return nil
+}
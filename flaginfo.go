@@ -0,0 +1,318 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FlagInfo holds optional documentation metadata for a flag, which is used in
+// the help output.
+type FlagInfo struct {
+	// Placeholder is the name for the flag value shown in the help, like PATH
+	// in `-file PATH`. It takes precedence over the back-quoted name in the
+	// flag usage string.
+	Placeholder string
+
+	// Category is the group name for the flag when the flags are listed by
+	// category. See the WithFlagListing option.
+	Category string
+
+	// Example is an example value for the flag, which is useful for flags
+	// defined with flag.Func or flag.TextVar that have no obvious value syntax.
+	Example string
+
+	// Env is the name of an environment variable, like TOOL_IP, that provides
+	// the flag value when the flag is not set on the command-line.
+	Env string
+
+	// Deprecated, when non-empty, marks the flag as deprecated in the help
+	// output with the message, like "use -addr instead", and a warning is
+	// printed when the flag is used on the command-line.
+	Deprecated string
+
+	// Advanced flags are omitted from the help output, unless all flags are
+	// requested with the -help-all flag or the "help -all" command, so that
+	// the power-user flags don't overwhelm the new users.
+	Advanced bool
+}
+
+// flagInfos holds the metadata for the flags. Flag sets are typically created
+// once per process, so the entries are never removed.
+var (
+	flagInfoMu sync.Mutex
+	flagInfos  = make(map[*flag.Flag]FlagInfo)
+)
+
+// SetFlagInfo attaches the documentation metadata to a flag defined in the
+// flag set. It is typically called from the Command method right after
+// defining the flag. It panics if the flag is not defined.
+func SetFlagInfo(fs *flag.FlagSet, name string, info FlagInfo) {
+	updateFlagInfo(lookupFlag(fs, name), func(v *FlagInfo) { *v = info })
+}
+
+// lookupFlag returns the flag defined in the flag set or panics.
+func lookupFlag(fs *flag.FlagSet, name string) *flag.Flag {
+	f := fs.Lookup(name)
+	if f == nil {
+		panic(fmt.Sprintf("flag %q is not defined in flag set %q", name, fs.Name()))
+	}
+	return f
+}
+
+// updateFlagInfo updates the metadata for a flag under the lock.
+func updateFlagInfo(f *flag.Flag, update func(*FlagInfo)) {
+	flagInfoMu.Lock()
+	defer flagInfoMu.Unlock()
+
+	info := flagInfos[f]
+	update(&info)
+	flagInfos[f] = info
+}
+
+// getFlagInfo returns a copy of the metadata for a flag, which is empty when
+// the flag has no metadata.
+func getFlagInfo(f *flag.Flag) FlagInfo {
+	flagInfoMu.Lock()
+	defer flagInfoMu.Unlock()
+	return flagInfos[f]
+}
+
+// SetFlagPlaceholder sets the name for the flag value shown in the help and
+// documentation outputs, like N in `-count N`. It panics if the flag is not
+// defined.
+func SetFlagPlaceholder(fs *flag.FlagSet, name, placeholder string) {
+	updateFlagInfo(lookupFlag(fs, name), func(v *FlagInfo) { v.Placeholder = placeholder })
+}
+
+// getPlaceholder returns the value placeholder and the usage string for a
+// flag. Usage string has the back-quotes removed as in flag.UnquoteUsage.
+// Placeholder is empty for boolean flags.
+func getPlaceholder(f *flag.Flag) (string, string) {
+	name, usage := flag.UnquoteUsage(f)
+	if len(name) == 0 {
+		return name, usage
+	}
+	if info := getFlagInfo(f); len(info.Placeholder) > 0 {
+		return info.Placeholder, usage
+	}
+	return name, usage
+}
+
+// listFlags returns all the flags in the flag set in the lexicographical
+// order.
+func listFlags(fs *flag.FlagSet) []*flag.Flag {
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+	return flags
+}
+
+// isZeroValue returns true if the default value of the flag is the zero value
+// for it's type. It is similar to the logic used by the standard library.
+func isZeroValue(f *flag.Flag) (zero bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			zero = false
+		}
+	}()
+
+	typ := reflect.TypeOf(f.Value)
+	var z reflect.Value
+	if typ.Kind() == reflect.Pointer {
+		z = reflect.New(typ.Elem())
+	} else {
+		z = reflect.Zero(typ)
+	}
+	v, ok := z.Interface().(flag.Value)
+	if !ok {
+		return false
+	}
+	return f.DefValue == v.String()
+}
+
+// isStringFlag returns true if the flag holds a string value, like the flags
+// defined with the flag.String function, whose defaults are quoted.
+func isStringFlag(f *flag.Flag) bool {
+	g, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	_, ok = g.Get().(string)
+	return ok
+}
+
+// flagAnnotations returns the parenthesized notes, like the default value and
+// the environment variable, that are appended to the flag usage string.
+func flagAnnotations(f *flag.Flag, l *FlagListing) string {
+	var notes []string
+	if !l.HideDefaults && !isZeroValue(f) {
+		value := f.DefValue
+		if !l.FullDefaults {
+			value = shortenDefault(value)
+		}
+		if isStringFlag(f) {
+			notes = append(notes, fmt.Sprintf("default %q", value))
+		} else {
+			notes = append(notes, fmt.Sprintf("default %v", value))
+		}
+	}
+	info := getFlagInfo(f)
+	if len(info.Env) > 0 {
+		notes = append(notes, "env "+info.Env)
+	}
+
+	var sb strings.Builder
+	if len(notes) > 0 {
+		fmt.Fprintf(&sb, " (%s)", strings.Join(notes, ", "))
+	}
+	if len(info.Example) > 0 {
+		fmt.Fprintf(&sb, " (example %q)", info.Example)
+	}
+	if len(info.Deprecated) > 0 {
+		fmt.Fprintf(&sb, " (%s)", msg(MsgHelpDeprecated, info.Deprecated))
+	}
+	return sb.String()
+}
+
+// maxDefaultLength is the maximum length for the default values in the flag
+// listings, unless the full values are requested.
+const maxDefaultLength = 40
+
+// shortenDefault returns the first line of the default value, which is
+// truncated with an ellipsis when it is longer than maxDefaultLength.
+func shortenDefault(value string) string {
+	first, _, multiline := strings.Cut(value, "\n")
+	runes := []rune(first)
+	if len(runes) > maxDefaultLength {
+		return string(runes[:maxDefaultLength-3]) + "..."
+	}
+	if multiline {
+		return first + "..."
+	}
+	return first
+}
+
+// commonFlags returns the flags that are not marked as advanced and the number
+// of advanced flags.
+func commonFlags(flags []*flag.Flag) ([]*flag.Flag, int) {
+	var common []*flag.Flag
+	for _, f := range flags {
+		if !getFlagInfo(f).Advanced {
+			common = append(common, f)
+		}
+	}
+	return common, len(flags) - len(common)
+}
+
+// setFlagsFromEnv sets the flags bound to environment variables with the
+// FlagInfo.Env field from the environment, which must happen before the
+// command-line flags are parsed, so that command-line takes precedence.
+func setFlagsFromEnv(fs *flag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		info := getFlagInfo(f)
+		if len(info.Env) == 0 || err != nil {
+			return
+		}
+		if value, ok := os.LookupEnv(info.Env); ok {
+			if serr := f.Value.Set(value); serr != nil {
+				err = fmt.Errorf("%s: %w", msg(MsgInvalidEnvValue, value, info.Env, f.Name), serr)
+			}
+		}
+	})
+	return err
+}
+
+// writeFlag prints a single flag in the same format as the standard library's
+// flag.PrintDefaults function or in a two column layout when a column width is
+// configured. Flag names are decorated and usage strings are wrapped as per
+// the style.
+func writeFlag(w io.Writer, f *flag.Flag, l *FlagListing, style helpStyle) {
+	name, usage := getPlaceholder(f)
+	// column alignment is computed without the color escape sequences
+	plain := 3 + len(f.Name)
+	if len(name) > 0 {
+		plain += 1 + len(name)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "  %s", style.flag("-"+f.Name))
+	if len(name) > 0 {
+		sb.WriteString(" ")
+		sb.WriteString(name)
+	}
+
+	indent := "\n    \t"
+	if l.Width > 0 {
+		indent = "\n" + strings.Repeat(" ", l.Width+2)
+		if plain <= l.Width {
+			fmt.Fprintf(&sb, "%*s", l.Width+2-plain, "")
+		} else {
+			sb.WriteString(indent)
+		}
+	} else if plain <= 4 {
+		// Boolean flags of one ASCII letter are so common we treat them
+		// specially, putting their usage on the same line.
+		sb.WriteString("\t")
+	} else {
+		sb.WriteString(indent)
+	}
+	// usage strings are wrapped with a hanging indent to the usage column
+	text, column := usage+flagAnnotations(f, l), 8
+	if l.Width > 0 {
+		column = l.Width + 2
+	}
+	if style.width > column {
+		text = wrapText(text, style.width-column)
+	}
+	sb.WriteString(strings.ReplaceAll(text, "\n", indent))
+	fmt.Fprintln(w, sb.String())
+}
+
+// writeFlagDefaults prints the flags as per the flag listing options, which
+// defaults to the same format as the standard library's flag.PrintDefaults
+// function, with the flag metadata included.
+func writeFlagDefaults(w io.Writer, flags []*flag.Flag, l *FlagListing, style helpStyle) {
+	if l.Less != nil {
+		flags = slices.Clone(flags)
+		sort.SliceStable(flags, func(i, j int) bool {
+			return l.Less(flags[i], flags[j])
+		})
+	}
+	if !l.GroupByCategory {
+		for _, f := range flags {
+			writeFlag(w, f, l, style)
+		}
+		return
+	}
+
+	var categories []string
+	groups := make(map[string][]*flag.Flag)
+	for _, f := range flags {
+		c := getFlagInfo(f).Category
+		if _, ok := groups[c]; !ok {
+			categories = append(categories, c)
+		}
+		groups[c] = append(groups[c], f)
+	}
+	sort.Strings(categories)
+	for i, c := range categories {
+		if len(c) > 0 {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, " %s\n", style.header(c+":"))
+		}
+		for _, f := range groups[c] {
+			writeFlag(w, f, l, style)
+		}
+	}
+}
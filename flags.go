@@ -0,0 +1,76 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import "flag"
+
+// OptionalState describes how a flag with an optional value appeared on the
+// command-line.
+type OptionalState int
+
+const (
+	// OptionalUnset indicates that the flag was not given.
+	OptionalUnset OptionalState = iota
+
+	// OptionalNoValue indicates that the flag was given without a value, as in
+	// `-color`.
+	OptionalNoValue
+
+	// OptionalWithValue indicates that the flag was given with an explicit
+	// value, as in `-color=never`.
+	OptionalWithValue
+)
+
+// OptionalValue is a flag.Value for flags that may appear with or without a
+// value. Explicit values must be given with the `-name=value` syntax because
+// the next argument is never consumed as the value.
+type OptionalValue struct {
+	state   OptionalState
+	value   string
+	implied string
+}
+
+// OptionalFlag defines a flag with an optional value in the flag set. When the
+// flag is given without a value, it takes the `implied` value.
+func OptionalFlag(fs *flag.FlagSet, name, implied, usage string) *OptionalValue {
+	v := &OptionalValue{implied: implied}
+	fs.Var(v, name, usage)
+	return v
+}
+
+// State returns the tri-state of the flag.
+func (v *OptionalValue) State() OptionalState {
+	return v.state
+}
+
+// Value returns the value of the flag, which is empty when the flag is not
+// given and the implied value when the flag is given without a value.
+func (v *OptionalValue) Value() string {
+	return v.value
+}
+
+// String implements the flag.Value interface.
+func (v *OptionalValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+// Set implements the flag.Value interface. It always records an explicit
+// value, like the values from the `-name=value` syntax or an environment
+// variable. Flags given without a value are recognized by the subcmd parser
+// only, because the standard library flag parser passes "true" for them.
+func (v *OptionalValue) Set(s string) error {
+	v.state, v.value = OptionalWithValue, s
+	return nil
+}
+
+// IsBoolFlag makes the flag parsers accept the flag without a value.
+func (v *OptionalValue) IsBoolFlag() bool {
+	return true
+}
+
+func (v *OptionalValue) setImplied() {
+	v.state, v.value = OptionalNoValue, v.implied
+}
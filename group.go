@@ -4,12 +4,16 @@ package subcmd
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
-	"os"
 	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/bvkgo/subcmd/cmdctx"
 )
 
 type cmdGroup struct {
@@ -17,9 +21,91 @@ type cmdGroup struct {
 	subcmds    []Command
 	specialCmd string
 	synopsis   string
+
+	// shortcuts holds the shortcuts file for the shortcut command group.
+	shortcuts string
+
+	// printCmd is set when the -print-command flag is seen.
+	printCmd bool
+
+	// debugResolve is set when the -debug-resolve flag is given anywhere in
+	// the arguments.
+	debugResolve bool
+
+	// warnErrors is set when the -warnings-as-errors flag is seen.
+	warnErrors bool
+
+	// offline is set when the -offline flag is seen.
+	offline bool
+
+	// seed holds the value of -seed flag, if it was seen.
+	seed seedValue
+
+	// color holds the value of -color flag, which selects the help colors.
+	color colorMode
+
+	// opts holds the options for the root command group.
+	opts options
+
+	// remote, when non-nil, populates the subcommands from a remote catalog.
+	remote Transport
+
+	// hidden is set when the group is marked with the Hidden function.
+	hidden bool
+
+	// validators check the arguments of all commands under the group.
+	validators []ArgsValidator
+
+	// annotations are set with the AnnotateGroup function.
+	annotations map[string]string
+
+	// deprecatedFlags holds the deprecated flags seen while resolving.
+	deprecatedFlags []*flag.Flag
+
+	// helpAll is set when the -all flag is seen for the help command.
+	helpAll bool
+
+	// verbose is set when the -verbose flag is seen for the flags command.
+	verbose bool
+
+	// topic is the help topic name for the "help <topic>" command.
+	topic string
+
+	// alias is the shortcut name expanded while resolving the arguments.
+	alias string
+
+	// copy is set when the -copy flag is seen.
+	copy bool
+
+	// noBrowser is set when the -no-browser flag is seen.
+	noBrowser bool
+
+	// nodes memoizes the Command method results for the root command group.
+	nodes *NodeCache
 }
 
-var specialCmds = []string{"help", "flags", "commands"}
+var specialCmds = []string{"help", "flags", "commands", "which"}
+
+// hiddenSpecialCmds are the special commands that are not listed in the help.
+var hiddenSpecialCmds = []string{"__spec"}
+
+// isBuiltin returns true if the special command is enabled for the root
+// command group. Special commands are disabled with the WithBuiltins option or
+// when a top-level command with the same name is defined.
+func (cg *cmdGroup) isBuiltin(name string) bool {
+	if !slices.Contains(specialCmds, name) {
+		return false
+	}
+	if cg.opts.builtins != nil && !slices.Contains(cg.opts.builtins, name) {
+		return false
+	}
+	for _, c := range cg.subcmds {
+		if cg.nodes.name(c) == name {
+			return false
+		}
+	}
+	return true
+}
 
 // Command implements the Command interface to turn a command group into a
 // subcommand.
@@ -34,24 +120,74 @@ type cmdData struct {
 }
 
 func (cg *cmdGroup) printFlags(ctx context.Context, w io.Writer, cmdseq []*cmdData) error {
-	fs := cmdseq[len(cmdseq)-1].fset
-	fs.SetOutput(w)
-	fs.PrintDefaults()
+	l := cg.opts.flagListing
+	if cg.verbose {
+		l.FullDefaults = true
+	}
+	writeFlagDefaults(w, listFlags(cmdseq[len(cmdseq)-1].fset), &l, cg.getHelpStyle(ctx))
 	return nil
 }
 
 func (cg *cmdGroup) printCommands(ctx context.Context, w io.Writer, cmdseq []*cmdData) error {
 	subcmds := getSubcommands(cmdseq)
+	for _, cat := range getCategoryCommands(cmdseq) {
+		subcmds = append(subcmds, [2]string{})
+		for _, c := range cat.Commands {
+			subcmds = append(subcmds, [2]string{c.Name, c.Synopsis})
+		}
+	}
+	for _, p := range getPluginCommands(cmdseq) {
+		subcmds = append(subcmds, [2]string{})
+		for _, c := range p.Commands {
+			subcmds = append(subcmds, [2]string{c.Name, c.Synopsis})
+		}
+	}
+	width := 15
+	for _, sub := range subcmds {
+		width = max(width, len(sub[0]))
+	}
 	for _, sub := range subcmds {
 		if len(sub[1]) > 0 {
-			fmt.Fprintf(w, "\t%-15s  %s\n", sub[0], sub[1])
+			fmt.Fprintf(w, "\t%-*s  %s\n", width, sub[0], sub[1])
 		} else {
-			fmt.Fprintf(w, "\t%-15s\n", sub[0])
+			fmt.Fprintf(w, "\t%s\n", sub[0])
 		}
 	}
 	return nil
 }
 
+// rerun returns a new root command group with the same command tree and
+// options to run another command from the tree.
+func (cg *cmdGroup) rerun() *cmdGroup {
+	return &cmdGroup{
+		flags:   cg.flags,
+		subcmds: cg.subcmds,
+		seed:    cg.seed,
+		color:   cg.color,
+		opts:    cg.opts,
+
+		debugResolve: cg.debugResolve,
+		printCmd:     cg.printCmd,
+		warnErrors:   cg.warnErrors,
+		offline:      cg.offline,
+		noBrowser:    cg.noBrowser,
+		copy:         cg.copy,
+		nodes:        cg.nodes,
+	}
+}
+
+// lookupBuiltin returns the framework defined flags that are recognized when
+// they are not defined by any of the commands.
+func (cg *cmdGroup) lookupBuiltin(name string) (*flag.Flag, bool) {
+	if name == "seed" {
+		return &flag.Flag{Name: name, Value: &cg.seed}, true
+	}
+	if name == "color" {
+		return &flag.Flag{Name: name, Value: &cg.color}, true
+	}
+	return nil, false
+}
+
 // resolve parses args into a subcommand sequence and arguments for the subcommand.
 func (cg *cmdGroup) resolve(ctx context.Context, args []string) ([]*cmdData, []string, error) {
 	type boolFlag interface {
@@ -63,11 +199,11 @@ func (cg *cmdGroup) resolve(ctx context.Context, args []string) ([]*cmdData, []s
 	prepCmdDataMap := func(cmds []Command) {
 		m := make(map[string]*cmdData)
 		for _, c := range cmds {
-			fs, fn := c.Command()
-			m[fs.Name()] = &cmdData{
-				fset: fs,
-				fun:  fn,
-				cmd:  c,
+			n := cg.nodes.NodeOf(c)
+			m[n.FlagSet.Name()] = &cmdData{
+				fset: n.FlagSet,
+				fun:  n.Main,
+				cmd:  unwrapHidden(c),
 			}
 		}
 		cmdDataMap = m
@@ -80,6 +216,9 @@ func (cg *cmdGroup) resolve(ctx context.Context, args []string) ([]*cmdData, []s
 			cmd:  cg,
 		},
 	}
+	if err := setFlagsFromEnv(flag.CommandLine); err != nil {
+		return nil, nil, err
+	}
 
 	lookup := func(s string) (*flag.Flag, bool) {
 		for i := len(cmdseq) - 1; i >= 0; i-- {
@@ -90,12 +229,40 @@ func (cg *cmdGroup) resolve(ctx context.Context, args []string) ([]*cmdData, []s
 		return nil, false
 	}
 
+	fail := func(index int, err error) error {
+		return &ParseError{
+			Index: index,
+			Token: args[index],
+			Path:  getPath(cmdseq),
+			Err:   err,
+		}
+	}
+
+	trace := func(format string, a ...any) {
+		if cg.debugResolve {
+			fmt.Fprintln(cg.stderr(), msg(MsgResolveTrace, fmt.Sprintf(format, a...)))
+		}
+	}
+	definedBy := func(name string) string {
+		path := getPath(cmdseq)
+		for i := len(cmdseq) - 1; i >= 0; i-- {
+			if cmdseq[i].fset.Lookup(name) != nil {
+				return strings.Join(path[:i+1], " ")
+			}
+		}
+		return "framework"
+	}
+	trace("resolving arguments %q", args)
+
+	expanded := false
+
 	var i int
 	for i = 0; i < len(args); i++ {
 		s := args[i]
 
 		// stop resolving subcmds and flags
 		if s == "--" {
+			trace("%d: %q ends the flags", i, s)
 			i++
 			break
 		}
@@ -104,107 +271,338 @@ func (cg *cmdGroup) resolve(ctx context.Context, args []string) ([]*cmdData, []s
 		if len(s) < 2 || s[0] != '-' {
 			// non-flag argument to the last subcmd
 			if len(cmdDataMap) == 0 {
+				trace("%d: %q starts the command arguments", i, s)
 				break
 			}
 
 			subcmd, ok := cmdDataMap[s]
 			if !ok {
 				// handle one of special commands: help, flags, commands
-				if len(cmdseq) == 1 && slices.Contains(specialCmds, s) {
+				if len(cmdseq) == 1 && (cg.isBuiltin(s) || slices.Contains(hiddenSpecialCmds, s)) {
+					trace("%d: %q selects the builtin %q command", i, s, s)
 					cg.specialCmd = s
+					// "which" takes the command-line to resolve as arguments
+					if s == "which" {
+						i++
+						break
+					}
 					continue
 				}
-				return nil, nil, fmt.Errorf("command not defined: %s", s)
+				// "help errors" takes the error codes as arguments
+				if len(cmdseq) == 1 && cg.specialCmd == "help" && s == "errors" {
+					trace("%d: %q selects the builtin error catalog", i, s)
+					cg.specialCmd = s
+					i++
+					break
+				}
+				// "help <topic>" displays a registered help topic
+				if len(cmdseq) == 1 && cg.specialCmd == "help" {
+					if _, ok := lookupHelpTopic(s); ok {
+						trace("%d: %q selects the help topic", i, s)
+						cg.specialCmd, cg.topic = "topic", s
+						continue
+					}
+				}
+				// replace a saved shortcut with it's arguments, but only once
+				if len(cmdseq) == 1 && !expanded {
+					if saved, ok := cg.lookupShortcut(s); ok {
+						trace("%d: %q is a shortcut for %q", i, s, saved)
+						args = append(append(append([]string{}, args[:i]...), saved...), args[i+1:]...)
+						expanded, cg.alias = true, s
+						i--
+						continue
+					}
+				}
+				return nil, nil, fail(i, errors.New(msg(MsgCommandNotDefined, s)))
 			}
 			cmdseq = append(cmdseq, subcmd)
+			if err := setFlagsFromEnv(subcmd.fset); err != nil {
+				return nil, nil, fail(i, err)
+			}
 
 			// handle subcommands from a command group
 			if sg, ok := subcmd.cmd.(*cmdGroup); ok {
+				if err := sg.fetchRemote(ctx, cg.offline); err != nil {
+					return nil, nil, fail(i, fmt.Errorf("%s: %w", msg(MsgCatalogFetchFailed, s), err))
+				}
+				trace("%d: %q enters the command group %q", i, s, strings.Join(getPath(cmdseq), " "))
 				prepCmdDataMap(sg.subcmds)
 				continue
 			}
 
+			// moved command stubs forward all remaining arguments
+			if mc, ok := subcmd.cmd.(*movedCmd); ok {
+				trace("%d: %q is moved to %q, so remaining arguments are forwarded", i, s, strings.Join(mc.newPath, " "))
+				i++
+				break
+			}
+
+			trace("%d: %q selects the command %q", i, s, strings.Join(getPath(cmdseq), " "))
+
 			// stop subcommand processing, but continue to resolve flags
 			prepCmdDataMap(nil)
 			continue
 		}
 
-		// remove the '-' or '--' prefix and '=...' suffix
-		name := s[1:]
-		if s[1] == '-' {
-			name = s[2:]
-		}
-		if len(name) == 0 || name[0] == '-' || name[0] == '=' {
-			return nil, nil, fmt.Errorf("bad flag syntax: %s", s)
+		// handle single character flags, which may be grouped together
+		if cg.opts.posix {
+			if s[1] == '-' {
+				return nil, nil, fail(i, errors.New(msg(MsgBadFlagSyntax, s)))
+			}
+			n, err := cg.parseShortFlags(args, i, lookup)
+			if err != nil {
+				return nil, nil, fail(i, err)
+			}
+			i += n
+			continue
 		}
-		value := ""
-		hasValue := strings.Contains(name, "=")
-		if hasValue {
-			pos := strings.Index(name, "=")
-			value = name[pos+1:]
-			name = name[:pos]
+
+		// remove the '-' or '--' prefix and '=...' suffix
+		name, value, hasValue, ok := splitFlag(s)
+		if !ok {
+			return nil, nil, fail(i, errors.New(msg(MsgBadFlagSyntax, s)))
 		}
 
 		// check for the flag in all the parent FlagSets
 		flag, ok := lookup(name)
 		if !ok {
-			if name == "help" || name == "h" {
+			flag, ok = cg.lookupBuiltin(name)
+		}
+		if !ok {
+			if (name == "help" || name == "h") && cg.isBuiltin("help") {
+				trace("%d: %q requests the help", i, s)
 				cg.specialCmd = "help"
 				continue
 			}
-			return nil, nil, fmt.Errorf("flag provided but not defined: -%s", name)
+			if name == "help-all" && cg.isBuiltin("help") {
+				trace("%d: %q requests the help with all flags", i, s)
+				cg.specialCmd, cg.helpAll = "help", true
+				continue
+			}
+			if p, ok := cg.switches()[name]; ok {
+				v, err := boolSwitch(value, hasValue)
+				if err != nil {
+					return nil, nil, fail(i, fmt.Errorf("%s: %w", msg(MsgInvalidBoolValue, value, name), err))
+				}
+				*p = v
+				if name == "debug-resolve" {
+					trace("%d: %q enables the trace for arguments %q", i, s, args)
+				}
+				continue
+			}
+			if _, ok := cg.lookupVersion(); ok && name == "version" {
+				trace("%d: %q requests the version", i, s)
+				cg.specialCmd = "version"
+				continue
+			}
+			if name == "all" && cg.specialCmd == "help" {
+				cg.helpAll = true
+				continue
+			}
+			if name == "verbose" && cg.specialCmd == "flags" {
+				cg.verbose = true
+				continue
+			}
+			return nil, nil, fail(i, errors.New(msg(MsgFlagNotDefined, name)))
+		}
+
+		trace("%d: %q is a flag defined by %q", i, s, definedBy(name))
+		if len(getFlagInfo(flag).Deprecated) > 0 {
+			cg.deprecatedFlags = append(cg.deprecatedFlags, flag)
+		}
+
+		// handle flag with an optional value, which takes the implied value when
+		// used without an argument.
+		if ov, ok := flag.Value.(*OptionalValue); ok {
+			if hasValue {
+				ov.state, ov.value = OptionalWithValue, value
+			} else {
+				ov.setImplied()
+			}
+			continue
 		}
 
 		// handle boolean flag, which doesn't need an argument.
 		if fv, ok := flag.Value.(boolFlag); ok && fv.IsBoolFlag() {
 			if hasValue {
 				if err := fv.Set(value); err != nil {
-					return nil, nil, fmt.Errorf("invalid boolean value %q for -%s: %w", value, name, err)
+					return nil, nil, fail(i, fmt.Errorf("%s: %w", msg(MsgInvalidBoolValue, value, name), err))
 				}
 			} else {
 				if err := fv.Set("true"); err != nil {
-					return nil, nil, fmt.Errorf("invalid boolean flag %s: %w", name, err)
+					return nil, nil, fail(i, fmt.Errorf("%s: %w", msg(MsgInvalidBoolFlag, name), err))
 				}
 			}
 			continue
 		}
 
 		// non-boolean flags must have a value, which might be the next argument.
+		pos := i
 		if !hasValue && i+1 < len(args) {
 			hasValue = true
 			value = args[i+1]
 			i++
 		}
 		if !hasValue {
-			return nil, nil, fmt.Errorf("flag needs an argument: -%s", name)
+			return nil, nil, fail(i, errors.New(msg(MsgFlagNeedsArgument, name)))
 		}
 		if err := flag.Value.Set(value); err != nil {
-			return nil, nil, fmt.Errorf("invalid value %q for flag -%s: %w", value, name, err)
+			return nil, nil, fail(pos, fmt.Errorf("%s: %w", msg(MsgInvalidFlagValue, value, name), err))
 		}
 	}
 
+	trace("resolved to %q with arguments %q", strings.Join(getPath(cmdseq), " "), args[i:])
 	return cmdseq, args[i:], nil
 }
 
+// switches returns the framework defined boolean flags, which are recognized
+// at all levels of the command path, with their destinations.
+func (cg *cmdGroup) switches() map[string]*bool {
+	return map[string]*bool{
+		"debug-resolve":      &cg.debugResolve,
+		"print-command":      &cg.printCmd,
+		"warnings-as-errors": &cg.warnErrors,
+		"offline":            &cg.offline,
+		"no-browser":         &cg.noBrowser,
+		"copy":               &cg.copy,
+	}
+}
+
+// boolSwitch returns the value of a framework defined boolean flag, which is
+// true when the flag is given without a value.
+func boolSwitch(value string, hasValue bool) (bool, error) {
+	if !hasValue {
+		return true, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseShortFlags parses a group of single character flags from args[i] as per
+// the POSIX utility syntax guidelines and returns the number of following
+// arguments consumed as the flag value.
+func (cg *cmdGroup) parseShortFlags(args []string, i int, lookup func(string) (*flag.Flag, bool)) (int, error) {
+	s := args[i][1:]
+	for j, r := range s {
+		name := string(r)
+		f, ok := lookup(name)
+		if !ok {
+			if name == "h" && cg.isBuiltin("help") {
+				cg.specialCmd = "help"
+				continue
+			}
+			return 0, errors.New(msg(MsgFlagNotDefined, name))
+		}
+		if len(getFlagInfo(f).Deprecated) > 0 {
+			cg.deprecatedFlags = append(cg.deprecatedFlags, f)
+		}
+
+		if ov, ok := f.Value.(*OptionalValue); ok {
+			ov.setImplied()
+			continue
+		}
+		if fv, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && fv.IsBoolFlag() {
+			if err := f.Value.Set("true"); err != nil {
+				return 0, fmt.Errorf("%s: %w", msg(MsgInvalidBoolFlag, name), err)
+			}
+			continue
+		}
+
+		// rest of the group, or the next argument, is the flag value
+		consumed := 0
+		value := s[j+len(name):]
+		if len(value) == 0 {
+			if i+1 >= len(args) {
+				return 0, errors.New(msg(MsgFlagNeedsArgument, name))
+			}
+			value, consumed = args[i+1], 1
+		}
+		if err := f.Value.Set(value); err != nil {
+			return 0, fmt.Errorf("%s: %w", msg(MsgInvalidFlagValue, value, name), err)
+		}
+		return consumed, nil
+	}
+	return 0, nil
+}
+
 func (cg *cmdGroup) run(ctx context.Context, args []string) error {
 	cmdseq, args, err := cg.resolve(ctx, args)
 	if err != nil {
 		return err
 	}
 
+	// commands run from within other commands share the invocation state
+	_, nested := rootKey.Value(ctx)
+
+	ctx = withRoot(ctx, cg)
+	ctx = cmdctx.WithOutput(ctx, cg.opts.stdout, cg.opts.stderr)
+	ctx = withPath(ctx, getPath(cmdseq))
+	ctx = cmdctx.WithFlags(ctx, cmdseq[len(cmdseq)-1].fset)
+	ctx = withRand(ctx, &cg.seed)
+	if cmdctx.TerminalInfo(ctx) == nil && cg.opts.stdout != nil && !isTerminalWriter(cg.opts.stdout) {
+		// redirected output has no terminal capabilities
+		ctx = WithTerminal(ctx, new(Terminal))
+	}
+	ctx = withTerminal(ctx)
+	ctx = withWarnings(ctx, cg.warnErrors)
+	ctx = withOffline(ctx, cg.offline)
+	ctx = withCopy(ctx, cg.copy)
+	ctx = withNoBrowser(ctx, cg.noBrowser)
+	ctx = withSummary(ctx)
+
+	if cg.printCmd {
+		return cg.printCommand(ctx, cg.stdout(), cmdseq, args)
+	}
+
 	switch cg.specialCmd {
 	case "help":
-		return cg.printHelp(ctx, os.Stdout, cmdseq)
+		return cg.pageHelp(ctx, cmdseq)
 	case "flags":
-		return cg.printFlags(ctx, os.Stdout, cmdseq)
+		return cg.printFlags(ctx, cg.stdout(), cmdseq)
 	case "commands":
-		return cg.printCommands(ctx, os.Stdout, cmdseq)
+		return cg.printCommands(ctx, cg.stdout(), cmdseq)
+	case "errors":
+		return printErrorCodes(cg.stdout(), args)
+	case "topic":
+		return cg.printHelpTopic(ctx, cg.topic)
+	case "which":
+		return cg.printWhich(ctx, cg.stdout(), args)
+	case "version":
+		v, _ := cg.lookupVersion()
+		return v.writeVersion(cg.stdout())
+	case "__spec":
+		return writeSpec(cg.stdout(), newSpec(cg.nodes, getPath(cmdseq)[0], cg.subcmds))
+	}
+
+	last := cmdseq[len(cmdseq)-1]
+	if last.fun == nil {
+		return cg.pageHelp(ctx, cmdseq)
 	}
 
-	fun := cmdseq[len(cmdseq)-1].fun
-	if fun == nil {
-		return cg.printHelp(ctx, os.Stdout, cmdseq)
+	if _, ok := last.cmd.(*movedCmd); !ok {
+		// moved command stubs forward the arguments to be validated at the new
+		// location
+		if err := validateArgs(cmdseq, args); err != nil {
+			return err
+		}
 	}
+	warnDeprecated(ctx, getPath(cmdseq)[1:], last.cmd, cg.deprecatedFlags)
 
-	return fun(ctx, args)
+	start := time.Now()
+	err = runRedirected(ctx, last.fset, func(ctx context.Context) error {
+		if limits, ok := getLimits(last.cmd); ok {
+			return runWithLimits(ctx, limits, last.fun, args)
+		}
+		return last.fun(ctx, args)
+	})
+	if !nested {
+		printSummary(ctx, cg.stderr())
+		printErrorHint(cg.stderr(), getPath(cmdseq)[0], err)
+		if nerr := cg.notify(ctx, getPath(cmdseq), start, err); nerr != nil {
+			fmt.Fprintln(cg.stderr(), nerr)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return checkWarnings(ctx)
 }
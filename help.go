@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -18,10 +20,28 @@ func numFlags(fs *flag.FlagSet) int {
 	return n
 }
 
+// getName returns the command name without memoizing the Command method
+// results, which is meant for the command groups and the commands outside of
+// a Run function call.
 func getName(c Command) string {
-	fs, _ := c.Command()
-	_, file := filepath.Split(fs.Name())
-	return file
+	var nc *NodeCache
+	return nc.name(c)
+}
+
+// SynopsisFromHelp returns the synopsis derived from a help text, which is
+// it's first sentence with the line breaks removed, as used for the commands
+// without an explicit synopsis.
+func SynopsisFromHelp(text string) string {
+	return getFirstLine(text)
+}
+
+// Synopsis returns the one line summary of the command as displayed in the
+// help and documentation outputs, which is taken from the optional
+// `interface{ CommandSynopsis() string }` method or derived from the help
+// text. Synopsis of a command group is the first sentence of it's
+// description.
+func Synopsis(c Command) string {
+	return getSynopsis(unwrapHidden(c))
 }
 
 func getFirstLine(text string) string {
@@ -38,9 +58,10 @@ func getFirstLine(text string) string {
 	return sb.String()
 }
 
-func getUsage(cmdpath []*cmdData) string {
+// getPath returns the names of all commands in the command path, starting
+// with the program name.
+func getPath(cmdpath []*cmdData) []string {
 	var words []string
-
 	for i, c := range cmdpath {
 		name := c.fset.Name()
 		if i == 0 {
@@ -48,6 +69,11 @@ func getUsage(cmdpath []*cmdData) string {
 		}
 		words = append(words, name)
 	}
+	return words
+}
+
+func getUsage(cmdpath []*cmdData) string {
+	words := getPath(cmdpath)
 
 	for _, c := range cmdpath {
 		if n := numFlags(c.fset); n != 0 {
@@ -60,36 +86,88 @@ func getUsage(cmdpath []*cmdData) string {
 		words = append(words, "<subcommand>")
 	}
 
-	words = append(words, "<args>")
+	words = append(words, getArgsUsage(cmdpath[len(cmdpath)-1].cmd))
 	return strings.Join(words, " ")
 }
 
+// getArgsUsage returns the placeholders for the positional arguments of a
+// command, which are collected through the optional
+// `interface{ CommandUsage() string }` method, like "<key> <value>".
+func getArgsUsage(c Command) string {
+	if v, ok := c.(interface{ CommandUsage() string }); ok {
+		if usage := strings.TrimSpace(v.CommandUsage()); len(usage) > 0 {
+			return usage
+		}
+	}
+	return "<args>"
+}
+
 func getHelpDoc(c Command) string {
+	return getVersionedHelpDoc(c, "")
+}
+
+// getVersionedHelpDoc returns the help text with sections that require a
+// newer tool version removed. All sections are kept when version is empty.
+// Help text is taken from the optional
+// `interface{ CommandDescription() string }` method, which falls back to the
+// CommandHelp method and the synopsis.
+func getVersionedHelpDoc(c Command, version string) string {
 	if v, ok := c.(*cmdGroup); ok {
 		return v.synopsis
 	}
+	if v, ok := c.(interface{ CommandDescription() string }); ok {
+		if text := filterHelp(v.CommandDescription(), version); len(strings.TrimSpace(text)) > 0 {
+			return text
+		}
+	}
 	if v, ok := c.(interface{ CommandHelp() string }); ok {
-		return v.CommandHelp()
+		return filterHelp(v.CommandHelp(), version)
 	}
-	return ""
+	return getVersionedSynopsis(c, version)
 }
 
 func getSynopsis(c Command) string {
+	return getVersionedSynopsis(c, "")
+}
+
+// getVersionedSynopsis returns the one line summary for the command from the
+// optional `interface{ CommandSynopsis() string }` method, which falls back to
+// the first sentence of the help text. Help text of a command group is it's
+// description.
+func getVersionedSynopsis(c Command, version string) string {
 	if v, ok := c.(*cmdGroup); ok {
-		return v.synopsis
+		return getFirstLine(v.synopsis)
+	}
+	if v, ok := c.(interface{ CommandSynopsis() string }); ok {
+		if synopsis := strings.TrimSpace(v.CommandSynopsis()); len(synopsis) > 0 {
+			return synopsis
+		}
 	}
 	if v, ok := c.(interface{ CommandHelp() string }); ok {
-		return getFirstLine(v.CommandHelp())
+		return getFirstLine(filterHelp(v.CommandHelp(), version))
 	}
 	return ""
 }
 
-func getFlags(c Command) (*flag.FlagSet, int) {
-	fs, _ := c.Command()
-	return fs, numFlags(fs)
+// isHidden returns true if the command must not be listed in the help and
+// documentation outputs.
+func isHidden(c Command) bool {
+	switch v := c.(type) {
+	case *movedCmd, *hiddenCmd:
+		return true
+	case *cmdGroup:
+		return v.hidden
+	case interface{ CommandHidden() bool }:
+		return v.CommandHidden()
+	}
+	return false
 }
 
-func getInheritedFlags(cmdpath []*cmdData) (*flag.FlagSet, int) {
+func getFlags(c *cmdData) []*flag.Flag {
+	return listFlags(c.fset)
+}
+
+func getInheritedFlags(cmdpath []*cmdData) []*flag.Flag {
 	flagMap := make(map[string][]*flag.Flag)
 	collector := func(f *flag.Flag) {
 		fs := flagMap[f.Name]
@@ -101,29 +179,42 @@ func getInheritedFlags(cmdpath []*cmdData) (*flag.FlagSet, int) {
 	for i := 0; i < len(cmdpath)-1; i++ {
 		cmdpath[i].fset.VisitAll(collector)
 	}
-	fset := flag.NewFlagSet("temp", flag.ContinueOnError)
+	var flags []*flag.Flag
 	for _, fs := range flagMap {
-		last := fs[len(fs)-1]
-		fset.Var(last.Value, last.Name, last.Usage)
+		flags = append(flags, fs[len(fs)-1])
 	}
-	return fset, numFlags(fset)
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags
 }
 
-// getSubcommands returns all subcommand names and synopsises as a pair.
+// getSubcommands returns all subcommand names and synopsises as a pair. Commands
+// provided by plugins and commands with a category are excluded, which are
+// returned by getPluginCommands and getCategoryCommands.
 func getSubcommands(cmdpath []*cmdData) [][2]string {
 	var spcmds [][2]string
-	if len(cmdpath) == 1 {
-		spcmds = [][2]string{
-			{"help", "Describe commands and flags"},
-			{"flags", "Describe all known flags"},
-			{"commands", "Lists all command names"},
+	if root, ok := cmdpath[0].cmd.(*cmdGroup); ok && len(cmdpath) == 1 {
+		builtins := [][2]string{
+			{"help", msg(MsgHelpCmdSynopsis)},
+			{"flags", msg(MsgFlagsCmdSynopsis)},
+			{"commands", msg(MsgCommandsCmdSynopsis)},
+			{"which", msg(MsgWhichCmdSynopsis)},
+		}
+		for _, b := range builtins {
+			if root.isBuiltin(b[0]) {
+				spcmds = append(spcmds, b)
+			}
 		}
 	}
 
 	var subcmds, groups [][2]string
 	if cg, ok := cmdpath[len(cmdpath)-1].cmd.(*cmdGroup); ok {
 		for _, c := range cg.subcmds {
-			n, s := getName(c), getSynopsis(c)
+			if isHidden(c) || len(getOrigin(c)) > 0 || len(getCategory(c)) > 0 {
+				continue
+			}
+			n, s := rootNodes(cmdpath).name(c), getListedSynopsis(c)
 			if _, ok := c.(*cmdGroup); ok {
 				groups = append(groups, [2]string{n, s})
 			} else {
@@ -158,44 +249,79 @@ func getSubcommands(cmdpath []*cmdData) [][2]string {
 }
 
 func (cg *cmdGroup) printHelp(ctx context.Context, w io.Writer, cmdpath []*cmdData) error {
-	last := cmdpath[len(cmdpath)-1]
+	t, err := getHelpTemplate(cmdpath[len(cmdpath)-1].cmd)
+	if err != nil {
+		return err
+	}
+	t, err = t.Clone()
+	if err != nil {
+		return err
+	}
+	style := cg.getHelpStyle(ctx)
+	return t.Funcs(style.funcs()).Execute(w, cg.getHelpData(cmdpath, style))
+}
 
-	usage := getUsage(cmdpath)
-	help := getHelpDoc(last.cmd)
-	subcmds := getSubcommands(cmdpath)
-	flags, nflags := getFlags(last.cmd)
-	iflags, niflags := getInheritedFlags(cmdpath)
+// printHelpAll prints the help for the last command in the command path and
+// all of it's visible subcommands, recursively, in one pass.
+func (cg *cmdGroup) printHelpAll(ctx context.Context, w io.Writer, cmdpath []*cmdData) error {
+	if len(cmdpath) > 1 {
+		fmt.Fprintf(w, "\n%s\n\n", strings.Repeat("-", 72))
+	}
+	if err := cg.printHelp(ctx, w, cmdpath); err != nil {
+		return err
+	}
 
-	fmt.Fprintf(w, "Usage: %s\n", usage)
-	if len(help) > 0 {
-		fmt.Fprintln(w)
-		// TODO: Format the help into 80 columns?
-		fmt.Fprintf(w, "%s\n", strings.TrimSpace(help))
+	sg, ok := cmdpath[len(cmdpath)-1].cmd.(*cmdGroup)
+	if !ok {
+		return nil
 	}
-	if len(subcmds) > 0 {
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "Subcommands:\n")
-		for _, sub := range subcmds {
-			if len(sub[1]) > 0 {
-				fmt.Fprintf(w, "\t%-15s  %s\n", sub[0], sub[1])
-			} else if len(sub[0]) > 0 {
-				fmt.Fprintf(w, "\t%-15s\n", sub[0])
-			} else {
-				fmt.Fprintln(w)
-			}
+	subcmds := slices.Clone(sg.subcmds)
+	sort.SliceStable(subcmds, func(i, j int) bool {
+		return cg.nodes.name(subcmds[i]) < cg.nodes.name(subcmds[j])
+	})
+	for _, c := range subcmds {
+		if isHidden(c) {
+			continue
+		}
+		n := cg.nodes.NodeOf(c)
+		sub := &cmdData{fset: n.FlagSet, fun: n.Main, cmd: c}
+		if err := cg.printHelpAll(ctx, w, append(slices.Clip(cmdpath), sub)); err != nil {
+			return err
 		}
 	}
-	if nflags > 0 {
-		fmt.Fprintln(w)
-		fmt.Fprintf(w, "Flags:\n")
-		flags.SetOutput(w)
-		flags.PrintDefaults()
+	return nil
+}
+
+// isSecret returns true if the flag value is marked as a secret, in which
+// case, it's value must not be displayed.
+func isSecret(f *flag.Flag) bool {
+	if v, ok := f.Value.(interface{ IsSecret() bool }); ok {
+		return v.IsSecret()
 	}
-	if niflags > 0 {
+	return false
+}
+
+// printCommand prints the resolved command path, effective flag values at
+// every level and the residual arguments without running the command.
+func (cg *cmdGroup) printCommand(ctx context.Context, w io.Writer, cmdpath []*cmdData, args []string) error {
+	fmt.Fprintln(w, msg(MsgLabelCommand, strings.Join(getPath(cmdpath), " ")))
+
+	var quoted []string
+	for _, arg := range args {
+		quoted = append(quoted, strconv.Quote(arg))
+	}
+	fmt.Fprintln(w, msg(MsgLabelArgs, strings.Join(quoted, " ")))
+
+	for i, c := range cmdpath {
+		if numFlags(c.fset) == 0 {
+			continue
+		}
 		fmt.Fprintln(w)
-		fmt.Fprintf(w, "Inherited Flags:\n")
-		iflags.SetOutput(w)
-		iflags.PrintDefaults()
+		fmt.Fprintln(w, msg(MsgLabelFlagsOf, strings.Join(getPath(cmdpath)[:i+1], " ")))
+		values := SnapshotFlags(c.fset)
+		for _, f := range listFlags(c.fset) {
+			fmt.Fprintf(w, "\t-%s=%s\n", f.Name, values[f.Name])
+		}
 	}
 	return nil
 }
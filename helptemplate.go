@@ -0,0 +1,271 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// HelpData holds the values available to the help templates.
+type HelpData struct {
+	// Path holds the command names from the program name to the command.
+	Path []string
+
+	// Usage is the usage line for the command, without the "Usage:" title.
+	Usage string
+
+	// Help is the detailed documentation for the command.
+	Help string
+
+	// Deprecated is the deprecation message for the command, which is empty
+	// when the command is not deprecated.
+	Deprecated string
+
+	// Tasks lists the common tasks for the top-level help output, which are
+	// configured with the WithLandingPage option.
+	Tasks []Example
+
+	// Subcommands lists the subcommands with their synopses, where entries
+	// with empty names separate the subcommand sections.
+	Subcommands []HelpCommand
+
+	// NameWidth is the width of the name column for the subcommand listings,
+	// which fits the longest subcommand name, so that the synopses are
+	// aligned.
+	NameWidth int
+
+	// Categories lists the subcommands that declare a category, grouped by
+	// the category names, which are listed after the other subcommands.
+	Categories []HelpCategory
+
+	// Plugins lists the subcommands provided by plugins, grouped by the plugin
+	// names, which are listed separately from the core subcommands.
+	Plugins []HelpPlugin
+
+	// Topics lists the documentation pages registered with the
+	// RegisterHelpTopic function, which are only listed for the top-level
+	// command.
+	Topics []HelpCommand
+
+	// Flags and InheritedFlags hold the rendered flag listings, which are
+	// empty when there are no flags.
+	Flags          string
+	InheritedFlags string
+
+	// AdvancedFlags is the number of advanced flags omitted from the flag
+	// listings.
+	AdvancedFlags int
+
+	// Examples lists the example invocations of the command.
+	Examples []Example
+
+	// SeeAlso lists the full command paths of the related commands.
+	SeeAlso []string
+
+	// Annotations holds the key/value annotations of the command, which are
+	// not displayed by the default template.
+	Annotations map[string]string
+}
+
+// HelpCommand describes a subcommand in the help output.
+type HelpCommand struct {
+	Name     string
+	Synopsis string
+}
+
+// DefaultHelpTemplate is the text/template used for the help output, which
+// can be used as the starting point for custom templates. Templates can use the
+// "header", "command" and "flag" functions to color the section headers,
+// command names and flag names as per the -color flag, and the "msg" and
+// "msgn" functions to format the messages, like the section titles, from the
+// message catalog, where "msgn" takes the count for the plural forms.
+const DefaultHelpTemplate = `{{msg "help-usage" | header}} {{.Usage}}
+{{- if .Deprecated}}
+
+{{msg "help-deprecated" .Deprecated | header}}
+{{- end}}
+{{- if .Help}}
+
+{{.Help}}
+{{- end}}
+{{- if .Tasks}}
+
+{{msg "help-common-tasks" | header}}
+{{- range $i, $e := .Tasks}}
+{{- if $i}}
+{{end}}
+{{- if $e.Description}}
+	# {{$e.Description}}
+{{- end}}
+	$ {{$e.Command}}
+{{- end}}
+{{- end}}
+{{- if .Subcommands}}
+
+{{msg "help-subcommands" | header}}
+{{- range .Subcommands}}
+{{if .Synopsis}}	{{printf "%-*s" $.NameWidth .Name | command}}  {{.Synopsis}}{{else if .Name}}	{{printf "%-*s" $.NameWidth .Name | command}}{{end}}
+{{- end}}
+{{- end}}
+{{- range .Categories}}
+
+{{printf "%s:" .Name | header}}
+{{- range .Commands}}
+{{if .Synopsis}}	{{printf "%-*s" $.NameWidth .Name | command}}  {{.Synopsis}}{{else}}	{{printf "%-*s" $.NameWidth .Name | command}}{{end}}
+{{- end}}
+{{- end}}
+{{- range .Plugins}}
+
+{{msg "help-plugin" .Name | header}}
+{{- range .Commands}}
+{{if .Synopsis}}	{{printf "%-*s" $.NameWidth .Name | command}}  {{.Synopsis}}{{else}}	{{printf "%-*s" $.NameWidth .Name | command}}{{end}}
+{{- end}}
+{{- end}}
+{{- if .Topics}}
+
+{{msg "help-topics" | header}}
+{{- range .Topics}}
+	{{printf "%-*s" $.NameWidth .Name | command}}  {{.Synopsis}}
+{{- end}}
+{{- end}}
+{{- if .Flags}}
+
+{{msg "help-flags" | header}}
+{{.Flags}}
+{{- end}}
+{{- if .InheritedFlags}}
+
+{{msg "help-inherited-flags" | header}}
+{{.InheritedFlags}}
+{{- end}}
+{{- if .AdvancedFlags}}
+
+{{msgn "help-advanced-flags" .AdvancedFlags .AdvancedFlags}}
+{{- end}}
+{{- if .Examples}}
+
+{{msg "help-examples" | header}}
+{{- range $i, $e := .Examples}}
+{{- if $i}}
+{{end}}
+{{- if $e.Description}}
+	# {{$e.Description}}
+{{- end}}
+	$ {{$e.Command}}
+{{- end}}
+{{- end}}
+{{- if .SeeAlso}}
+
+{{msg "help-see-also" | header}}
+{{- range .SeeAlso}}
+	{{command .}}
+{{- end}}
+{{- end}}
+`
+
+var (
+	helpTemplateMu sync.Mutex
+	helpTemplate   = template.Must(newHelpTemplate(DefaultHelpTemplate))
+)
+
+// SetHelpTemplate replaces the text/template used for the help output of all
+// commands. Template is executed with a *HelpData value. Commands can also
+// override the template individually through the optional
+// `interface{ CommandHelpTemplate() string }` method.
+func SetHelpTemplate(text string) error {
+	t, err := newHelpTemplate(text)
+	if err != nil {
+		return err
+	}
+	helpTemplateMu.Lock()
+	defer helpTemplateMu.Unlock()
+	helpTemplate = t
+	return nil
+}
+
+// newHelpTemplate parses the help template text with the styling functions
+// defined, which are replaced with the actual styles before the execution.
+func newHelpTemplate(text string) (*template.Template, error) {
+	return template.New("help").Funcs(helpStyle{}.funcs()).Parse(text)
+}
+
+// getHelpTemplate returns the help template for the command.
+func getHelpTemplate(c Command) (*template.Template, error) {
+	if v, ok := c.(interface{ CommandHelpTemplate() string }); ok {
+		return newHelpTemplate(v.CommandHelpTemplate())
+	}
+	helpTemplateMu.Lock()
+	defer helpTemplateMu.Unlock()
+	return helpTemplate, nil
+}
+
+// getHelpData collects the help template values for the last command in the
+// command path.
+func (cg *cmdGroup) getHelpData(cmdpath []*cmdData, style helpStyle) *HelpData {
+	last := cmdpath[len(cmdpath)-1]
+
+	data := &HelpData{
+		Path:  getPath(cmdpath),
+		Usage: getUsage(cmdpath),
+		Help:  wrapText(strings.TrimSpace(getHelpDoc(last.cmd)), style.width),
+	}
+	data.Deprecated = getDeprecated(last.cmd)
+	data.Examples = getExamples(last.cmd)
+	data.Annotations = getAnnotations(last.cmd)
+	data.Categories = getCategoryCommands(cmdpath)
+	data.Plugins = getPluginCommands(cmdpath)
+	if len(cmdpath) == 1 {
+		data.Topics = getHelpTopics()
+		if page := cg.opts.landing; page != nil {
+			data.Help = wrapText(strings.TrimSpace(page.Intro), style.width)
+			data.Tasks = page.Tasks
+		}
+	}
+	for _, p := range getSeeAlso(last.cmd) {
+		data.SeeAlso = append(data.SeeAlso, data.Path[0]+" "+p)
+	}
+	for _, sub := range getSubcommands(cmdpath) {
+		data.Subcommands = append(data.Subcommands, HelpCommand{Name: sub[0], Synopsis: sub[1]})
+	}
+
+	data.NameWidth = nameWidth(data.Subcommands)
+	for _, cat := range data.Categories {
+		data.NameWidth = max(data.NameWidth, nameWidth(cat.Commands))
+	}
+	for _, p := range data.Plugins {
+		data.NameWidth = max(data.NameWidth, nameWidth(p.Commands))
+	}
+	data.NameWidth = max(data.NameWidth, nameWidth(data.Topics))
+
+	flags, iflags := getFlags(last), getInheritedFlags(cmdpath)
+	if !cg.helpAll {
+		var n, m int
+		flags, n = commonFlags(flags)
+		iflags, m = commonFlags(iflags)
+		data.AdvancedFlags = n + m
+	}
+
+	var sb strings.Builder
+	if len(flags) > 0 {
+		writeFlagDefaults(&sb, flags, &cg.opts.flagListing, style)
+		data.Flags = strings.TrimSuffix(sb.String(), "\n")
+	}
+	sb.Reset()
+	if len(iflags) > 0 {
+		writeFlagDefaults(&sb, iflags, &cg.opts.flagListing, style)
+		data.InheritedFlags = strings.TrimSuffix(sb.String(), "\n")
+	}
+	return data
+}
+
+// nameWidth returns the width of the name column to align the synopses of
+// the commands, which is at least 15 characters.
+func nameWidth(cmds []HelpCommand) int {
+	width := 15
+	for _, c := range cmds {
+		width = max(width, len(c.Name))
+	}
+	return width
+}
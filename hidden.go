@@ -0,0 +1,37 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import "flag"
+
+// hiddenCmd wraps a command to hide it from the help and documentation
+// outputs.
+type hiddenCmd struct {
+	cmd Command
+}
+
+func (h *hiddenCmd) Command() (*flag.FlagSet, MainFunc) {
+	return h.cmd.Command()
+}
+
+// Hidden marks a command as hidden, so that it can still be run, but is not
+// listed by the "commands" and "help" outputs or in the generated docs, which
+// is useful for internal and debugging commands. Commands can also hide
+// themselves conditionally through the optional
+// `interface{ CommandHidden() bool }` method.
+func Hidden(c Command) Command {
+	if cg, ok := c.(*cmdGroup); ok {
+		cg.hidden = true
+		return cg
+	}
+	return &hiddenCmd{cmd: c}
+}
+
+// unwrapHidden returns the command wrapped by the Hidden function, so that
+// it's optional methods are visible once the command is selected.
+func unwrapHidden(c Command) Command {
+	if hc, ok := c.(*hiddenCmd); ok {
+		return hc.cmd
+	}
+	return c
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// WriteHTML writes a single HTML page with the reference documentation for
+// every command in the tree, with a navigation list linking to the sections
+// of all commands. Program name is taken from the running binary, as in the
+// help output.
+func WriteHTML(w io.Writer, root []Command) error {
+	return writeHTML(w, newDocsRoot(new(NodeCache), root))
+}
+
+func writeHTML(w io.Writer, root *cmdData) error {
+	pages := collectPages([]*cmdData{root})
+	link := func(path []string) string {
+		return "#" + anchorName(strings.Join(path, " "))
+	}
+
+	var buf bytes.Buffer
+	writeHTMLHeader(&buf, getPath(pages[0])[0])
+	fmt.Fprintf(&buf, "<nav>\n<ul>\n")
+	for _, cmdpath := range pages {
+		path := getPath(cmdpath)
+		fmt.Fprintf(&buf, "<li><a href=\"%s\">%s</a></li>\n", link(path), html.EscapeString(strings.Join(path, " ")))
+	}
+	fmt.Fprintf(&buf, "</ul>\n</nav>\n")
+	for _, cmdpath := range pages {
+		writeHTMLSection(&buf, cmdpath, link)
+	}
+	fmt.Fprintf(&buf, "</body>\n</html>\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// GenHTMLPages writes an HTML page for every command in the tree into the
+// directory, named by joining the command path with dashes, like
+// `tool-db-scan.html`, where every page links to it's parent commands and
+// subcommands.
+func GenHTMLPages(root []Command, dir string) error {
+	return genHTMLPages(newDocsRoot(new(NodeCache), root), dir)
+}
+
+func genHTMLPages(root *cmdData, dir string) error {
+	link := func(path []string) string {
+		return anchorName(strings.Join(path, " ")) + ".html"
+	}
+	for _, cmdpath := range collectPages([]*cmdData{root}) {
+		path := getPath(cmdpath)
+
+		var buf bytes.Buffer
+		writeHTMLHeader(&buf, strings.Join(path, " "))
+		fmt.Fprintf(&buf, "<nav>\n")
+		for i := range path[:len(path)-1] {
+			fmt.Fprintf(&buf, "<a href=\"%s\">%s</a> &rsaquo;\n", link(path[:i+1]), html.EscapeString(path[i]))
+		}
+		fmt.Fprintf(&buf, "%s\n</nav>\n", html.EscapeString(path[len(path)-1]))
+		writeHTMLSection(&buf, cmdpath, link)
+		fmt.Fprintf(&buf, "</body>\n</html>\n")
+		if err := os.WriteFile(filepath.Join(dir, link(path)), buf.Bytes(), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newDocsRoot returns the root of the command path for the generated docs,
+// which uses the global flags and the program name of the running binary.
+func newDocsRoot(nodes *NodeCache, root []Command) *cmdData {
+	return &cmdData{
+		fset: flag.CommandLine,
+		cmd:  &cmdGroup{flags: flag.CommandLine, subcmds: root, nodes: nodes},
+	}
+}
+
+// collectPages returns the command paths for the last command in the command
+// path and all of it's visible subcommands, recursively, in the sorted order.
+func collectPages(cmdpath []*cmdData) [][]*cmdData {
+	pages := [][]*cmdData{cmdpath}
+	cg, ok := cmdpath[len(cmdpath)-1].cmd.(*cmdGroup)
+	if !ok {
+		return pages
+	}
+	nodes := rootNodes(cmdpath)
+	walkTree(nodes, nil, cg.subcmds, func(_ []string, c Command) bool {
+		if !isHidden(c) {
+			n := nodes.NodeOf(c)
+			sub := &cmdData{fset: n.FlagSet, fun: n.Main, cmd: c}
+			pages = append(pages, collectPages(append(slices.Clip(cmdpath), sub))...)
+		}
+		return false
+	})
+	return pages
+}
+
+func writeHTMLHeader(w io.Writer, title string) {
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(w, "<title>%s</title>\n</head>\n<body>\n", html.EscapeString(title))
+}
+
+// htmlTitle returns the section title from the message catalog without the
+// trailing colon.
+func htmlTitle(id MessageID, a ...any) string {
+	return html.EscapeString(strings.TrimSuffix(msg(id, a...), ":"))
+}
+
+func writeHTMLCommands(w io.Writer, title string, path []string, cmds []HelpCommand, link func([]string) string) {
+	if len(cmds) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "<h3>%s</h3>\n<dl>\n", title)
+	for _, c := range cmds {
+		target := link(append(slices.Clip(path), c.Name))
+		fmt.Fprintf(w, "<dt><a href=\"%s\"><code>%s</code></a></dt>\n", target, html.EscapeString(c.Name))
+		fmt.Fprintf(w, "<dd>%s</dd>\n", html.EscapeString(c.Synopsis))
+	}
+	fmt.Fprintf(w, "</dl>\n")
+}
+
+func writeHTMLFlags(w io.Writer, title string, flags []*flag.Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "<h3>%s</h3>\n<dl>\n", title)
+	for _, f := range flags {
+		name, usage := getPlaceholder(f)
+		if len(name) > 0 {
+			fmt.Fprintf(w, "<dt><code>-%s <var>%s</var></code></dt>\n", html.EscapeString(f.Name), html.EscapeString(name))
+		} else {
+			fmt.Fprintf(w, "<dt><code>-%s</code></dt>\n", html.EscapeString(f.Name))
+		}
+		fmt.Fprintf(w, "<dd>%s</dd>\n", html.EscapeString(usage+flagAnnotations(f, &FlagListing{FullDefaults: true})))
+	}
+	fmt.Fprintf(w, "</dl>\n")
+}
+
+// writeHTMLSection writes the documentation for the last command in the
+// command path, where the `link` function returns the link target for a
+// command path.
+func writeHTMLSection(w io.Writer, cmdpath []*cmdData, link func([]string) string) {
+	path := getPath(cmdpath)
+	last := cmdpath[len(cmdpath)-1]
+
+	fmt.Fprintf(w, "<section id=\"%s\">\n", anchorName(strings.Join(path, " ")))
+	fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(strings.Join(path, " ")))
+	if synopsis := getSynopsis(last.cmd); len(synopsis) > 0 {
+		fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(synopsis))
+	}
+	fmt.Fprintf(w, "<pre>%s</pre>\n", html.EscapeString(getUsage(cmdpath)))
+	if help := strings.TrimSpace(getHelpDoc(last.cmd)); len(help) > 0 && help != getSynopsis(last.cmd) {
+		for _, para := range strings.Split(help, "\n\n") {
+			fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(strings.TrimSpace(para)))
+		}
+	}
+
+	var subcmds []HelpCommand
+	root, _ := cmdpath[0].cmd.(*cmdGroup)
+	for _, sub := range getSubcommands(cmdpath) {
+		if len(sub[0]) > 0 && !(len(cmdpath) == 1 && root.isBuiltin(sub[0])) {
+			subcmds = append(subcmds, HelpCommand{Name: sub[0], Synopsis: sub[1]})
+		}
+	}
+	writeHTMLCommands(w, htmlTitle(MsgHelpSubcommands), path, subcmds, link)
+	for _, cat := range getCategoryCommands(cmdpath) {
+		writeHTMLCommands(w, html.EscapeString(cat.Name), path, cat.Commands, link)
+	}
+	for _, p := range getPluginCommands(cmdpath) {
+		writeHTMLCommands(w, htmlTitle(MsgHelpPlugin, p.Name), path, p.Commands, link)
+	}
+
+	writeHTMLFlags(w, htmlTitle(MsgHelpFlags), getFlags(last))
+	writeHTMLFlags(w, htmlTitle(MsgHelpInheritedFlags), getInheritedFlags(cmdpath))
+
+	if examples := getExamples(last.cmd); len(examples) > 0 {
+		fmt.Fprintf(w, "<h3>%s</h3>\n", htmlTitle(MsgHelpExamples))
+		for _, e := range examples {
+			if len(e.Description) > 0 {
+				fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(e.Description))
+			}
+			fmt.Fprintf(w, "<pre>$ %s</pre>\n", html.EscapeString(e.Command))
+		}
+	}
+
+	if annotations := getAnnotations(last.cmd); len(annotations) > 0 {
+		fmt.Fprintf(w, "<dl class=\"annotations\">\n")
+		for _, k := range sortedKeys(annotations) {
+			fmt.Fprintf(w, "<dt>%s</dt>\n<dd>%s</dd>\n", html.EscapeString(k), html.EscapeString(annotations[k]))
+		}
+		fmt.Fprintf(w, "</dl>\n")
+	}
+
+	if related := getSeeAlso(last.cmd); len(related) > 0 {
+		fmt.Fprintf(w, "<h3>%s</h3>\n<ul>\n", htmlTitle(MsgHelpSeeAlso))
+		for _, p := range related {
+			target := link(append([]string{path[0]}, strings.Fields(p)...))
+			fmt.Fprintf(w, "<li><a href=\"%s\"><code>%s %s</code></a></li>\n", target, html.EscapeString(path[0]), html.EscapeString(p))
+		}
+		fmt.Fprintf(w, "</ul>\n")
+	}
+	fmt.Fprintf(w, "</section>\n")
+}
+
+type htmlCmd struct {
+	dir string
+}
+
+func (c *htmlCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet("html", flag.ContinueOnError)
+	fset.StringVar(&c.dir, "dir", "", "output directory for one page per command")
+	return fset, c.run
+}
+
+func (c *htmlCmd) CommandHelp() string {
+	return `Generates HTML reference docs for all commands.
+
+Prints a single HTML page with the documentation for all commands, or writes
+one page per command into the output directory when the -dir flag is set.
+`
+}
+
+func (c *htmlCmd) run(ctx context.Context, args []string) error {
+	root, ok := rootKey.Value(ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", msg(MsgNeedsRun, "docs html"), os.ErrInvalid)
+	}
+	if len(c.dir) > 0 {
+		return genHTMLPages(newDocsRoot(root.nodes, root.subcmds), c.dir)
+	}
+	return writeHTML(getStdout(ctx), newDocsRoot(root.nodes, root.subcmds))
+}
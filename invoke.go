@@ -0,0 +1,56 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Invoke runs another command from the same command tree, identified by it's
+// command names from the root, with the arguments. It is meant to be called
+// from within a command's main function, so that composite commands can
+// reuse other commands through the framework instead of executing
+// themselves. Arguments can include the flags for the invoked command.
+//
+// NOTE: Invoked commands are resolved through the command tree again, but
+// their Command methods are not called again, so the flags keep the values
+// set by the earlier runs unless they are set in the arguments.
+func Invoke(ctx context.Context, path []string, args ...string) error {
+	return invoke(ctx, strings.Join(path, " "), append(slices.Clip(path), args...))
+}
+
+// invoke resolves and runs the command-line arguments through the root
+// command group saved in the context.
+func invoke(ctx context.Context, name string, argv []string) error {
+	root, ok := rootKey.Value(ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", msg(MsgNeedsRun, name), os.ErrInvalid)
+	}
+	return root.rerun().run(ctx, argv)
+}
+
+// InvokeOutput is like Invoke, but captures the standard output of the invoked
+// command and returns it to the caller instead of printing to the terminal.
+//
+// Output is captured from the writer returned by the cmdctx.Stdout function,
+// which the framework also uses for it's own output, so output written
+// directly to `os.Stdout` is not captured.
+func InvokeOutput(ctx context.Context, path []string, args ...string) ([]byte, error) {
+	root, ok := rootKey.Value(ctx)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", msg(MsgNeedsRun, strings.Join(path, " ")), os.ErrInvalid)
+	}
+
+	var buf bytes.Buffer
+	next := root.rerun()
+	next.opts.stdout = &buf
+	// captured output has no terminal capabilities
+	ctx = WithTerminal(ctx, new(Terminal))
+	err := next.run(ctx, append(slices.Clip(path), args...))
+	return buf.Bytes(), err
+}
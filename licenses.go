@@ -0,0 +1,91 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+type licensesCmd struct {
+	fsys fs.FS
+	list bool
+}
+
+// Licenses creates a "licenses" command that prints the third-party license
+// notices from the file system, which is typically an embed.FS with one file
+// per dependency, like `licenses/golang.org/x/sys/LICENSE`. Notices for only
+// the files with the given name prefixes are printed when arguments are
+// given.
+func Licenses(fsys fs.FS) Command {
+	return &licensesCmd{fsys: fsys}
+}
+
+func (c *licensesCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet("licenses", flag.ContinueOnError)
+	fset.BoolVar(&c.list, "list", false, "prints only the license file names")
+	return fset, c.run
+}
+
+func (c *licensesCmd) CommandHelp() string {
+	return `Prints the third-party license notices.
+
+Prints the license notices for all third-party components included in the
+program. Arguments, when given, select the license files by their name
+prefixes.
+`
+}
+
+func (c *licensesCmd) run(ctx context.Context, args []string) error {
+	var files []string
+	err := fs.WalkDir(c.fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && matchPrefix(path, args) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 && len(args) > 0 {
+		return fmt.Errorf("%s: %w", msg(MsgLicenseNotFound, strings.Join(args, " ")), os.ErrNotExist)
+	}
+
+	w := getStdout(ctx)
+	for i, file := range files {
+		if c.list {
+			fmt.Fprintln(w, file)
+			continue
+		}
+		data, err := fs.ReadFile(c.fsys, file)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "==> %s <==\n\n%s\n", file, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+// matchPrefix returns true if the path starts with any of the prefixes or
+// when there are no prefixes.
+func matchPrefix(path string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
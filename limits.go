@@ -0,0 +1,69 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// Limits defines soft resource budgets for a command. Zero values indicate no
+// limits.
+type Limits struct {
+	// Memory is the soft memory limit in bytes, which is enforced through the
+	// Go runtime's memory limit (same as GOMEMLIMIT) for the process.
+	Memory int64
+
+	// Time is the maximum run time for the command, which is enforced as a
+	// deadline on the context.
+	Time time.Duration
+
+	// CPUs is the maximum number of CPUs that can run the Go code of the
+	// command simultaneously, which is enforced through the Go runtime's
+	// GOMAXPROCS setting for the process. CPU time is not limited.
+	CPUs int
+}
+
+// getLimits returns the resource limits declared by a command through the
+// optional `interface{ CommandLimits() Limits }` method.
+func getLimits(c Command) (Limits, bool) {
+	if v, ok := c.(interface{ CommandLimits() Limits }); ok {
+		return v.CommandLimits(), true
+	}
+	return Limits{}, false
+}
+
+// runWithLimits runs the main function with it's resource limits enforced
+// and reports the budgets that were exceeded as warnings.
+func runWithLimits(ctx context.Context, limits Limits, fun MainFunc, args []string) error {
+	if limits.Memory > 0 {
+		old := debug.SetMemoryLimit(limits.Memory)
+		defer debug.SetMemoryLimit(old)
+	}
+	if limits.CPUs > 0 {
+		old := runtime.GOMAXPROCS(limits.CPUs)
+		defer runtime.GOMAXPROCS(old)
+	}
+	if limits.Time > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.Time)
+		defer cancel()
+	}
+
+	err := fun(ctx, args)
+
+	if limits.Time > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		Warn(ctx, "%s", msg(MsgTimeBudgetExceeded, limits.Time))
+	}
+	if limits.Memory > 0 {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		if used := int64(ms.Sys - ms.HeapReleased); used > limits.Memory {
+			Warn(ctx, "%s", msg(MsgMemoryBudgetExceeded, limits.Memory, used))
+		}
+	}
+	return err
+}
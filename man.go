@@ -0,0 +1,218 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// GenManPages writes troff formatted man pages for every command in the tree
+// into the directory. Man page names are formed by joining the command path
+// with dashes, like `tool-db-scan.1`, where the program name is taken from
+// the running binary, as in the help output.
+func GenManPages(root []Command, dir string) error {
+	return genManPages(dir, []*cmdData{newDocsRoot(new(NodeCache), root)})
+}
+
+func genManPages(dir string, cmdpath []*cmdData) error {
+	var buf bytes.Buffer
+	writeManPage(&buf, cmdpath)
+	name := strings.Join(getPath(cmdpath), "-") + ".1"
+	if err := os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	cg, ok := cmdpath[len(cmdpath)-1].cmd.(*cmdGroup)
+	if !ok {
+		return nil
+	}
+	for _, c := range cg.subcmds {
+		if isHidden(c) {
+			continue
+		}
+		n := rootNodes(cmdpath).NodeOf(c)
+		sub := &cmdData{fset: n.FlagSet, fun: n.Main, cmd: c}
+		if err := genManPages(dir, append(slices.Clip(cmdpath), sub)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manEscape escapes the text for use in troff documents.
+func manEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	s = strings.ReplaceAll(s, "-", `\-`)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func writeManFlags(w io.Writer, flags []*flag.Flag) {
+	for _, f := range flags {
+		name, usage := getPlaceholder(f)
+		fmt.Fprintf(w, ".TP\n")
+		if len(name) > 0 {
+			fmt.Fprintf(w, ".BI \"%s\" \" %s\"\n", manEscape("-"+f.Name), manEscape(name))
+		} else {
+			fmt.Fprintf(w, ".B \"%s\"\n", manEscape("-"+f.Name))
+		}
+		fmt.Fprintf(w, "%s\n", manEscape(usage+flagAnnotations(f, &FlagListing{FullDefaults: true})))
+	}
+}
+
+func writeManPage(w io.Writer, cmdpath []*cmdData) {
+	path := getPath(cmdpath)
+	last := cmdpath[len(cmdpath)-1]
+	title := strings.Join(path, "-")
+
+	fmt.Fprintf(w, ".TH \"%s\" 1\n", manEscape(strings.ToUpper(title)))
+	fmt.Fprintf(w, ".SH NAME\n")
+	if synopsis := getSynopsis(last.cmd); len(synopsis) > 0 {
+		fmt.Fprintf(w, "%s \\- %s\n", manEscape(title), manEscape(synopsis))
+	} else {
+		fmt.Fprintf(w, "%s\n", manEscape(title))
+	}
+
+	usage := getUsage(cmdpath)
+	cmdname := strings.Join(path, " ")
+	fmt.Fprintf(w, ".SH SYNOPSIS\n")
+	fmt.Fprintf(w, ".B %s\n", manEscape(cmdname))
+	if rest := strings.TrimSpace(strings.TrimPrefix(usage, cmdname)); len(rest) > 0 {
+		fmt.Fprintf(w, "%s\n", manEscape(rest))
+	}
+
+	if help := strings.TrimSpace(getHelpDoc(last.cmd)); len(help) > 0 {
+		fmt.Fprintf(w, ".SH DESCRIPTION\n")
+		for i, para := range strings.Split(help, "\n\n") {
+			if i > 0 {
+				fmt.Fprintf(w, ".PP\n")
+			}
+			fmt.Fprintf(w, "%s\n", manEscape(strings.TrimSpace(para)))
+		}
+	}
+
+	var subcmds [][2]string
+	root, _ := cmdpath[0].cmd.(*cmdGroup)
+	for _, sub := range getSubcommands(cmdpath) {
+		if len(sub[0]) > 0 && !(len(cmdpath) == 1 && root.isBuiltin(sub[0])) {
+			subcmds = append(subcmds, sub)
+		}
+	}
+	if len(subcmds) > 0 {
+		fmt.Fprintf(w, ".SH COMMANDS\n")
+		for _, sub := range subcmds {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(sub[0]), manEscape(sub[1]))
+		}
+	}
+	for _, cat := range getCategoryCommands(cmdpath) {
+		fmt.Fprintf(w, ".SH \"%s\"\n", manEscape(strings.ToUpper(cat.Name)))
+		for _, c := range cat.Commands {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(c.Name), manEscape(c.Synopsis))
+			subcmds = append(subcmds, [2]string{c.Name, c.Synopsis})
+		}
+	}
+	for _, p := range getPluginCommands(cmdpath) {
+		title := strings.TrimSuffix(msg(MsgHelpPlugin, p.Name), ":")
+		fmt.Fprintf(w, ".SH \"%s\"\n", manEscape(strings.ToUpper(title)))
+		for _, c := range p.Commands {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(c.Name), manEscape(c.Synopsis))
+			subcmds = append(subcmds, [2]string{c.Name, c.Synopsis})
+		}
+	}
+
+	if flags := getFlags(last); len(flags) > 0 {
+		fmt.Fprintf(w, ".SH OPTIONS\n")
+		writeManFlags(w, flags)
+	}
+	if iflags := getInheritedFlags(cmdpath); len(iflags) > 0 {
+		fmt.Fprintf(w, ".SH INHERITED OPTIONS\n")
+		writeManFlags(w, iflags)
+	}
+
+	if examples := getExamples(last.cmd); len(examples) > 0 {
+		fmt.Fprintf(w, ".SH EXAMPLES\n")
+		for _, e := range examples {
+			fmt.Fprintf(w, ".PP\n")
+			if len(e.Description) > 0 {
+				fmt.Fprintf(w, "%s\n", manEscape(e.Description))
+			}
+			fmt.Fprintf(w, ".RS\n.nf\n%s\n.fi\n.RE\n", manEscape(e.Command))
+		}
+	}
+
+	if annotations := getAnnotations(last.cmd); len(annotations) > 0 {
+		fmt.Fprintf(w, ".SH ANNOTATIONS\n")
+		for _, k := range sortedKeys(annotations) {
+			fmt.Fprintf(w, ".TP\n.B %s\n%s\n", manEscape(k), manEscape(annotations[k]))
+		}
+	}
+
+	var seeAlso []string
+	if len(path) > 1 {
+		seeAlso = append(seeAlso, strings.Join(path[:len(path)-1], "-"))
+	}
+	for _, sub := range subcmds {
+		seeAlso = append(seeAlso, title+"-"+sub[0])
+	}
+	for _, p := range getSeeAlso(last.cmd) {
+		if name := path[0] + "-" + anchorName(p); !slices.Contains(seeAlso, name) {
+			seeAlso = append(seeAlso, name)
+		}
+	}
+	if len(seeAlso) > 0 {
+		fmt.Fprintf(w, ".SH SEE ALSO\n")
+		for i, name := range seeAlso {
+			sep := ","
+			if i == len(seeAlso)-1 {
+				sep = ""
+			}
+			fmt.Fprintf(w, ".BR %s (1)%s\n", manEscape(name), sep)
+		}
+	}
+}
+
+type manCmd struct {
+	dir string
+}
+
+func (c *manCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet("man", flag.ContinueOnError)
+	fset.StringVar(&c.dir, "dir", ".", "output directory for the man pages")
+	return fset, c.run
+}
+
+func (c *manCmd) CommandHelp() string {
+	return `Generates man pages for all commands.
+
+Man pages are written into the output directory, one file per command, named
+by joining the command path with dashes.
+`
+}
+
+func (c *manCmd) run(ctx context.Context, args []string) error {
+	root, ok := rootKey.Value(ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", msg(MsgNeedsRun, "docs man"), os.ErrInvalid)
+	}
+	return genManPages(c.dir, []*cmdData{newDocsRoot(root.nodes, root.subcmds)})
+}
+
+// Docs creates a "docs" command group with subcommands to generate the
+// documentation for the command tree, like man pages, HTML pages and usage
+// sections, and to export and compare the command tree specs.
+func Docs() Command {
+	return Group("docs", "Generate documentation.", new(manCmd), new(htmlCmd), new(usageCmd), new(specCmd), new(diffCmd))
+}
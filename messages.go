@@ -0,0 +1,360 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// MessageID identifies a user-facing message produced by the framework.
+type MessageID string
+
+// Message identifiers for all framework produced messages.
+const (
+	MsgCommandNotDefined    MessageID = "command-not-defined"
+	MsgBadFlagSyntax        MessageID = "bad-flag-syntax"
+	MsgFlagNotDefined       MessageID = "flag-not-defined"
+	MsgInvalidBoolValue     MessageID = "invalid-bool-value"
+	MsgInvalidBoolFlag      MessageID = "invalid-bool-flag"
+	MsgFlagNeedsArgument    MessageID = "flag-needs-argument"
+	MsgInvalidFlagValue     MessageID = "invalid-flag-value"
+	MsgUnexpectedArgument   MessageID = "unexpected-argument"
+	MsgMissingArgument      MessageID = "missing-argument"
+	MsgArgumentMismatch     MessageID = "argument-mismatch"
+	MsgProgramNotDefined    MessageID = "program-not-defined"
+	MsgNeedsRun             MessageID = "needs-run"
+	MsgWarningPrefix        MessageID = "warning-prefix"
+	MsgWarningsAsErrors     MessageID = "warnings-as-errors"
+	MsgCommandMoved         MessageID = "command-moved"
+	MsgCommandDeprecated    MessageID = "command-deprecated"
+	MsgFlagDeprecated       MessageID = "flag-deprecated"
+	MsgDeprecatedMarker     MessageID = "deprecated-marker"
+	MsgCommandCycle         MessageID = "command-cycle"
+	MsgTimeBudgetExceeded   MessageID = "time-budget-exceeded"
+	MsgMemoryBudgetExceeded MessageID = "memory-budget-exceeded"
+	MsgChecksFailed         MessageID = "checks-failed"
+	MsgChecksSummary        MessageID = "checks-summary"
+	MsgRunSummary           MessageID = "run-summary"
+	MsgShortcutNeedsArgs    MessageID = "shortcut-needs-args"
+	MsgShortcutIsCommand    MessageID = "shortcut-is-command"
+	MsgShortcutInvalid      MessageID = "shortcut-invalid"
+	MsgShortcutsFileInvalid MessageID = "shortcuts-file-invalid"
+	MsgBenchNeedsCommand    MessageID = "bench-needs-command"
+	MsgBenchRunFailed       MessageID = "bench-run-failed"
+	MsgBenchNoRuns          MessageID = "bench-no-runs"
+	MsgStartFailed          MessageID = "start-failed"
+	MsgStarted              MessageID = "started"
+	MsgCheckStatus          MessageID = "check-status"
+	MsgAlreadyRunning       MessageID = "already-running"
+	MsgPidFileWriteFailed   MessageID = "pid-file-write-failed"
+	MsgPidFileInvalidPid    MessageID = "pid-file-invalid-pid"
+	MsgPidFileInvalidArg    MessageID = "pid-file-invalid-arg"
+	MsgNotRunning           MessageID = "not-running"
+	MsgStalePidFile         MessageID = "stale-pid-file"
+	MsgRunning              MessageID = "running"
+	MsgStopped              MessageID = "stopped"
+	MsgStopFailed           MessageID = "stop-failed"
+	MsgDidNotExit           MessageID = "did-not-exit"
+	MsgInvalidColorMode     MessageID = "invalid-color-mode"
+	MsgSeeErrorCode         MessageID = "see-error-code"
+	MsgErrorCodeNotDefined  MessageID = "error-code-not-defined"
+	MsgSpecFileInvalid      MessageID = "spec-file-invalid"
+	MsgDiffNeedsSpecs       MessageID = "diff-needs-specs"
+	MsgIncompatibleChanges  MessageID = "incompatible-changes"
+	MsgSeeAlsoNotDefined    MessageID = "see-also-not-defined"
+	MsgMovedPathMismatch    MessageID = "moved-path-mismatch"
+	MsgCatalogFetchFailed   MessageID = "catalog-fetch-failed"
+	MsgLicenseNotFound      MessageID = "license-not-found"
+	MsgWizardEquivalent     MessageID = "wizard-equivalent"
+	MsgWizardConfirm        MessageID = "wizard-confirm"
+	MsgWizardCanceled       MessageID = "wizard-canceled"
+	MsgEquivalentPrefix     MessageID = "equivalent-prefix"
+	MsgClipboardCopied      MessageID = "clipboard-copied"
+	MsgClipboardFailed      MessageID = "clipboard-failed"
+	MsgDocFormatInvalid     MessageID = "doc-format-invalid"
+	MsgOpenURL              MessageID = "open-url"
+	MsgNoticeSucceeded      MessageID = "notice-succeeded"
+	MsgNoticeFailed         MessageID = "notice-failed"
+	MsgNotifyFailed         MessageID = "notify-failed"
+	MsgInvalidEnvValue      MessageID = "invalid-env-value"
+
+	MsgHelpUsage           MessageID = "help-usage"
+	MsgHelpSubcommands     MessageID = "help-subcommands"
+	MsgHelpPlugin          MessageID = "help-plugin"
+	MsgHelpFlags           MessageID = "help-flags"
+	MsgHelpInheritedFlags  MessageID = "help-inherited-flags"
+	MsgHelpExamples        MessageID = "help-examples"
+	MsgHelpSeeAlso         MessageID = "help-see-also"
+	MsgHelpTopics          MessageID = "help-topics"
+	MsgHelpCommonTasks     MessageID = "help-common-tasks"
+	MsgHelpAdvancedFlags   MessageID = "help-advanced-flags"
+	MsgHelpDeprecated      MessageID = "help-deprecated"
+	MsgHelpCmdSynopsis     MessageID = "help-cmd-synopsis"
+	MsgFlagsCmdSynopsis    MessageID = "flags-cmd-synopsis"
+	MsgCommandsCmdSynopsis MessageID = "commands-cmd-synopsis"
+	MsgWhichCmdSynopsis    MessageID = "which-cmd-synopsis"
+
+	MsgLabelCommand    MessageID = "label-command"
+	MsgLabelArgs       MessageID = "label-args"
+	MsgLabelFlagsOf    MessageID = "label-flags-of"
+	MsgLabelShortcut   MessageID = "label-shortcut"
+	MsgLabelMoved      MessageID = "label-moved"
+	MsgLabelSource     MessageID = "label-source"
+	MsgSourceBuiltin   MessageID = "source-builtin"
+	MsgLabelVersion    MessageID = "label-version"
+	MsgLabelRevision   MessageID = "label-revision"
+	MsgLabelTime       MessageID = "label-time"
+	MsgLabelGo         MessageID = "label-go"
+	MsgVersionUnknown  MessageID = "version-unknown"
+	MsgVersionModified MessageID = "version-modified"
+	MsgBenchRuns       MessageID = "bench-runs"
+	MsgBenchMean       MessageID = "bench-mean"
+	MsgBenchMin        MessageID = "bench-min"
+	MsgBenchP50        MessageID = "bench-p50"
+	MsgBenchP90        MessageID = "bench-p90"
+	MsgBenchP99        MessageID = "bench-p99"
+	MsgBenchMax        MessageID = "bench-max"
+	MsgCheckPassed     MessageID = "check-passed"
+	MsgCheckWarned     MessageID = "check-warned"
+	MsgCheckFailed     MessageID = "check-failed"
+	MsgCheckHint       MessageID = "check-hint"
+	MsgResolveTrace    MessageID = "resolve-trace"
+
+	MsgSpecCommandAdded       MessageID = "spec-command-added"
+	MsgSpecCommandRemoved     MessageID = "spec-command-removed"
+	MsgSpecFlagAdded          MessageID = "spec-flag-added"
+	MsgSpecFlagRemoved        MessageID = "spec-flag-removed"
+	MsgSpecFlagTypeChanged    MessageID = "spec-flag-type-changed"
+	MsgSpecFlagDefaultChanged MessageID = "spec-flag-default-changed"
+)
+
+// Message holds the `fmt` style formats for a message. Formats are selected
+// by the count for pluralization.
+type Message struct {
+	// One is the format used when count is one.
+	One string
+
+	// Other is the format used for all other counts. When empty, the One format
+	// is used for all counts.
+	Other string
+}
+
+var defaultMessages = map[MessageID]Message{
+	MsgCommandNotDefined:    {One: "command not defined: %s"},
+	MsgBadFlagSyntax:        {One: "bad flag syntax: %s"},
+	MsgFlagNotDefined:       {One: "flag provided but not defined: -%s"},
+	MsgInvalidBoolValue:     {One: "invalid boolean value %q for -%s"},
+	MsgInvalidBoolFlag:      {One: "invalid boolean flag %s"},
+	MsgFlagNeedsArgument:    {One: "flag needs an argument: -%s"},
+	MsgInvalidFlagValue:     {One: "invalid value %q for flag -%s"},
+	MsgUnexpectedArgument:   {One: "unexpected argument %q"},
+	MsgMissingArgument:      {One: "argument %d is missing"},
+	MsgArgumentMismatch:     {One: "argument %q does not match %s"},
+	MsgProgramNotDefined:    {One: "program not defined: %s"},
+	MsgNeedsRun:             {One: "command %q must be run through subcmd.Run"},
+	MsgWarningPrefix:        {One: "warning: "},
+	MsgWarningsAsErrors:     {One: "%d warning was reported", Other: "%d warnings were reported"},
+	MsgCommandMoved:         {One: "command %q has moved to %q"},
+	MsgCommandDeprecated:    {One: "command %q is deprecated: %s"},
+	MsgFlagDeprecated:       {One: "flag -%s is deprecated: %s"},
+	MsgDeprecatedMarker:     {One: "[DEPRECATED]"},
+	MsgCommandCycle:         {One: "moved commands forward in a loop: %s"},
+	MsgTimeBudgetExceeded:   {One: "command exceeded it's time budget of %s"},
+	MsgMemoryBudgetExceeded: {One: "command exceeded it's memory budget of %d bytes (used %d bytes)"},
+	MsgChecksFailed:         {One: "%d health check failed", Other: "%d health checks failed"},
+	MsgChecksSummary:        {One: "%d passed, %d warning, %d failed", Other: "%d passed, %d warnings, %d failed"},
+	MsgRunSummary:           {One: "%d succeeded, %d failed in %s"},
+	MsgShortcutNeedsArgs:    {One: "shortcut needs a name and the command to save"},
+	MsgShortcutIsCommand:    {One: "shortcut name %q is already a command"},
+	MsgShortcutInvalid:      {One: "shortcut %q is not a valid invocation"},
+	MsgShortcutsFileInvalid: {One: "could not parse shortcuts file %q"},
+	MsgBenchNeedsCommand:    {One: "bench needs a command to run"},
+	MsgBenchRunFailed:       {One: "run %d failed"},
+	MsgBenchNoRuns:          {One: "No runs were completed"},
+	MsgStartFailed:          {One: "could not start background process"},
+	MsgStarted:              {One: "Started background process %d; process id is saved in %s"},
+	MsgCheckStatus:          {One: "Check the status with '%s'"},
+	MsgAlreadyRunning:       {One: "background process %d is already running (pid file %s)"},
+	MsgPidFileWriteFailed:   {One: "could not write pid file for process %d"},
+	MsgPidFileInvalidPid:    {One: "invalid process id in pid file %q"},
+	MsgPidFileInvalidArg:    {One: "invalid argument in pid file %q"},
+	MsgNotRunning:           {One: "Background process is not running"},
+	MsgStalePidFile:         {One: "Background process %d is not running (stale pid file %s)"},
+	MsgRunning:              {One: "Background process %d is running"},
+	MsgStopped:              {One: "Stopped background process %d"},
+	MsgStopFailed:           {One: "could not stop process %d"},
+	MsgDidNotExit:           {One: "process %d did not exit"},
+	MsgInvalidColorMode:     {One: "color mode must be one of auto, always or never"},
+	MsgSeeErrorCode:         {One: "see '%s help errors %s'"},
+	MsgErrorCodeNotDefined:  {One: "error code not defined: %s"},
+	MsgSpecFileInvalid:      {One: "could not parse spec file %q"},
+	MsgDiffNeedsSpecs:       {One: "diff needs the old and new spec files"},
+	MsgIncompatibleChanges:  {One: "%d incompatible command-line change", Other: "%d incompatible command-line changes"},
+	MsgSeeAlsoNotDefined:    {One: "command %q refers to an undefined related command %q"},
+	MsgMovedPathMismatch:    {One: "moved command stub for %q is registered at %q"},
+	MsgCatalogFetchFailed:   {One: "could not fetch the command catalog for %q"},
+	MsgLicenseNotFound:      {One: "no license files match %q"},
+	MsgWizardEquivalent:     {One: "Equivalent command:"},
+	MsgWizardConfirm:        {One: "Proceed? [Y/n]"},
+	MsgWizardCanceled:       {One: "canceled by the user"},
+	MsgEquivalentPrefix:     {One: "equivalent:"},
+	MsgClipboardCopied:      {One: "Copied to the clipboard"},
+	MsgClipboardFailed:      {One: "Clipboard is not available; copy the value from the output instead"},
+	MsgDocFormatInvalid:     {One: "docs format %q is not one of markdown, asciidoc or rst"},
+	MsgOpenURL:              {One: "Open %s in your web browser"},
+	MsgNoticeSucceeded:      {One: "%s completed in %s"},
+	MsgNoticeFailed:         {One: "%s failed after %s: %v"},
+	MsgNotifyFailed:         {One: "could not send the completion notice"},
+	MsgInvalidEnvValue:      {One: "invalid value %q in environment variable %s for flag -%s"},
+
+	MsgHelpUsage:           {One: "Usage:"},
+	MsgHelpSubcommands:     {One: "Subcommands:"},
+	MsgHelpPlugin:          {One: "Commands provided by plugin %s:"},
+	MsgHelpFlags:           {One: "Flags:"},
+	MsgHelpInheritedFlags:  {One: "Inherited Flags:"},
+	MsgHelpExamples:        {One: "Examples:"},
+	MsgHelpSeeAlso:         {One: "See Also:"},
+	MsgHelpTopics:          {One: "Help Topics:"},
+	MsgHelpCommonTasks:     {One: "Common Tasks:"},
+	MsgHelpAdvancedFlags:   {One: "%d advanced flag is not shown; use -help-all to show all flags.", Other: "%d advanced flags are not shown; use -help-all to show all flags."},
+	MsgHelpDeprecated:      {One: "DEPRECATED: %s"},
+	MsgHelpCmdSynopsis:     {One: "Describe commands and flags"},
+	MsgFlagsCmdSynopsis:    {One: "Describe all known flags"},
+	MsgCommandsCmdSynopsis: {One: "Lists all command names"},
+	MsgWhichCmdSynopsis:    {One: "Shows which command runs for the arguments"},
+
+	MsgLabelCommand:    {One: "Command: %s"},
+	MsgLabelArgs:       {One: "Args: %s"},
+	MsgLabelFlagsOf:    {One: "Flags of %s:"},
+	MsgLabelShortcut:   {One: "Shortcut: %s"},
+	MsgLabelMoved:      {One: "Moved: %s -> %s"},
+	MsgLabelSource:     {One: "Source: %s"},
+	MsgSourceBuiltin:   {One: "builtin"},
+	MsgLabelVersion:    {One: "Version: %s"},
+	MsgLabelRevision:   {One: "Revision: %s"},
+	MsgLabelTime:       {One: "Time: %s"},
+	MsgLabelGo:         {One: "Go: %s"},
+	MsgVersionUnknown:  {One: "(unknown)"},
+	MsgVersionModified: {One: "%s (modified)"},
+	MsgBenchRuns:       {One: "runs: %d"},
+	MsgBenchMean:       {One: "mean: %s"},
+	MsgBenchMin:        {One: "min:  %s"},
+	MsgBenchP50:        {One: "p50:  %s"},
+	MsgBenchP90:        {One: "p90:  %s"},
+	MsgBenchP99:        {One: "p99:  %s"},
+	MsgBenchMax:        {One: "max:  %s"},
+	MsgCheckPassed:     {One: "[PASS] %s"},
+	MsgCheckWarned:     {One: "[WARN] %s: %v"},
+	MsgCheckFailed:     {One: "[FAIL] %s: %v"},
+	MsgCheckHint:       {One: "       hint: %s"},
+	MsgResolveTrace:    {One: "resolve: %s"},
+
+	MsgSpecCommandAdded:       {One: "Added command `%s`"},
+	MsgSpecCommandRemoved:     {One: "Removed command `%s`"},
+	MsgSpecFlagAdded:          {One: "Added flag `-%[2]s` to `%[1]s`"},
+	MsgSpecFlagRemoved:        {One: "Removed flag `-%[2]s` from `%[1]s`"},
+	MsgSpecFlagTypeChanged:    {One: "Changed type of flag `-%[2]s` of `%[1]s` from %[3]s to %[4]s"},
+	MsgSpecFlagDefaultChanged: {One: "Changed default of flag `-%[2]s` of `%[1]s` from %[3]q to %[4]q"},
+}
+
+// DefaultMessages returns a copy of the default English message catalog, which
+// can be used as the base for translations.
+func DefaultMessages() map[MessageID]Message {
+	return maps.Clone(defaultMessages)
+}
+
+// Formatter interface defines the requirements for formatting the framework
+// messages, so that they can be passed through a translation pipeline.
+type Formatter interface {
+	// Format returns the message for the id formatted with the arguments. Count
+	// is the number used for selecting plural forms, which is one when a
+	// message has no plural forms.
+	Format(id MessageID, count int, args ...any) string
+}
+
+// Catalog is a Formatter backed by a message catalog. Messages missing from
+// the catalog are taken from the default English catalog.
+type Catalog map[MessageID]Message
+
+// Format implements the Formatter interface.
+func (c Catalog) Format(id MessageID, count int, args ...any) string {
+	m, ok := c[id]
+	if !ok {
+		if m, ok = defaultMessages[id]; !ok {
+			return fmt.Sprint(append([]any{id}, args...)...)
+		}
+	}
+	format := m.One
+	if count != 1 && len(m.Other) > 0 {
+		format = m.Other
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+var formatter atomic.Value
+
+// SetFormatter replaces the formatter used for all framework messages.
+func SetFormatter(f Formatter) {
+	formatter.Store(&f)
+}
+
+var (
+	localesMu sync.Mutex
+	locales   = make(map[string]Formatter)
+)
+
+// AddLocale registers the formatter for a locale, like "pt_BR" or "pt", which
+// can be selected with the SetLocale function.
+func AddLocale(locale string, f Formatter) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[locale] = f
+}
+
+// SetLocale selects the formatter registered for the locale as the formatter
+// for all framework messages. Locale is taken from the LC_ALL, LC_MESSAGES or
+// LANG environment variables when it is empty. Encoding and territory parts
+// of the locale are dropped when there is no exact match, so that "pt_BR.UTF-8"
+// selects the "pt_BR" formatter or the "pt" formatter. It returns false and
+// leaves the formatter unchanged when no formatter is registered for the
+// locale.
+func SetLocale(locale string) bool {
+	if len(locale) == 0 {
+		for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if locale = os.Getenv(name); len(locale) > 0 {
+				break
+			}
+		}
+	}
+
+	localesMu.Lock()
+	defer localesMu.Unlock()
+
+	base, _, _ := strings.Cut(locale, ".")
+	lang, _, _ := strings.Cut(base, "_")
+	for _, name := range []string{locale, base, lang} {
+		if f, ok := locales[name]; ok && len(name) > 0 {
+			SetFormatter(f)
+			return true
+		}
+	}
+	return false
+}
+
+// msgn formats a message with the count used for pluralization.
+func msgn(id MessageID, count int, args ...any) string {
+	if f, ok := formatter.Load().(*Formatter); ok {
+		return (*f).Format(id, count, args...)
+	}
+	return Catalog(nil).Format(id, count, args...)
+}
+
+// msg formats a message that has no plural forms.
+func msg(id MessageID, args ...any) string {
+	return msgn(id, 1, args...)
+}
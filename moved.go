@@ -0,0 +1,57 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+type movedCmd struct {
+	oldPath []string
+	newPath []string
+}
+
+// MovedCommand creates a hidden command stub that must be placed at the old
+// location of a renamed or moved command. Running the stub prints a warning
+// about the move and runs the command at the new location with all the
+// remaining command-line arguments. Paths are space separated command names
+// from the root, like "db backup". It panics if either path is empty.
+func MovedCommand(oldPath, newPath string) Command {
+	c := &movedCmd{
+		oldPath: strings.Fields(oldPath),
+		newPath: strings.Fields(newPath),
+	}
+	if len(c.oldPath) == 0 || len(c.newPath) == 0 {
+		panic(fmt.Sprintf("moved command paths %q and %q must not be empty", oldPath, newPath))
+	}
+	return c
+}
+
+func (c *movedCmd) Command() (*flag.FlagSet, MainFunc) {
+	return flag.NewFlagSet(c.oldPath[len(c.oldPath)-1], flag.ContinueOnError), c.run
+}
+
+// movedKey holds the old paths of the moved command stubs that are run so far
+// in the invocation.
+var movedKey = cmdctx.NewKey[[]string]("moved")
+
+func (c *movedCmd) run(ctx context.Context, args []string) error {
+	// defend against moved command stubs forwarding to each other in a loop
+	oldPath := strings.Join(c.oldPath, " ")
+	chain, _ := movedKey.Value(ctx)
+	if slices.Contains(chain, oldPath) {
+		return &CycleError{Chain: append(slices.Clip(chain), oldPath)}
+	}
+	ctx = movedKey.With(ctx, append(slices.Clip(chain), oldPath))
+
+	if _, ok := rootKey.Value(ctx); ok {
+		Warn(ctx, "%s", msg(MsgCommandMoved, strings.Join(c.oldPath, " "), strings.Join(c.newPath, " ")))
+	}
+	return invoke(ctx, oldPath, append(slices.Clip(c.newPath), args...))
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"flag"
+	"path/filepath"
+	"reflect"
+	"sync"
+)
+
+// Node holds a command from the command tree with the flag set and the main
+// function returned by it's Command method, which is useful for tools that
+// inspect the command tree.
+type Node struct {
+	Cmd     Command
+	FlagSet *flag.FlagSet
+	Main    MainFunc
+
+	once sync.Once
+}
+
+// NodeCache memoizes the results of the Command methods, so that the help,
+// documentation and validation features can look at the commands repeatedly
+// while the Command method is called only once per command instance. Every
+// Run function call uses a new cache, which is shared by the commands invoked
+// with the Invoke function. Zero value is ready to use and a nil cache
+// memoizes nothing.
+type NodeCache struct {
+	mu    sync.Mutex
+	nodes map[Command]*Node
+}
+
+// NodeOf returns the node for the command. Command method is called only on
+// the first use of a command instance and the results are reused afterwards.
+// Only the commands implemented with pointer types are memoized, because
+// other types may not be usable as map keys.
+func (nc *NodeCache) NodeOf(c Command) *Node {
+	if nc == nil || reflect.TypeOf(c).Kind() != reflect.Pointer {
+		n := &Node{Cmd: c}
+		n.FlagSet, n.Main = c.Command()
+		return n
+	}
+
+	// hidden commands share the node with the wrapped command
+	key := unwrapHidden(c)
+
+	nc.mu.Lock()
+	n, ok := nc.nodes[key]
+	if !ok {
+		if nc.nodes == nil {
+			nc.nodes = make(map[Command]*Node)
+		}
+		n = &Node{Cmd: key}
+		nc.nodes[key] = n
+	}
+	nc.mu.Unlock()
+
+	// Command method is called without the lock, because it may look up other
+	// commands, like a wrapper command does.
+	n.once.Do(func() {
+		n.FlagSet, n.Main = key.Command()
+	})
+	return n
+}
+
+// name returns the command name, which is the base name of it's flag set.
+func (nc *NodeCache) name(c Command) string {
+	_, file := filepath.Split(nc.NodeOf(c).FlagSet.Name())
+	return file
+}
+
+// rootNodes returns the node cache of the root command group of the command
+// path.
+func rootNodes(cmdpath []*cmdData) *NodeCache {
+	if root, ok := cmdpath[0].cmd.(*cmdGroup); ok {
+		return root.nodes
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Notice describes a completed command for the notifiers.
+type Notice struct {
+	// Command is the resolved command path, starting with the program name.
+	Command string
+
+	// Elapsed is the run time of the command.
+	Elapsed time.Duration
+
+	// Err is the error returned by the command, if any.
+	Err error
+}
+
+// Notifier delivers the completion notices for the long running commands.
+type Notifier func(ctx context.Context, n *Notice) error
+
+// WithNotifications enables the completion notices for the commands that run
+// longer than the `after` duration, so that operators are notified when long
+// backups or scans complete. Notifier errors are reported on the standard
+// error.
+func WithNotifications(after time.Duration, notifier Notifier) Option {
+	return func(opts *options) {
+		opts.notifyAfter = after
+		opts.notifier = notifier
+	}
+}
+
+// message returns the text for the notice.
+func (n *Notice) message() string {
+	elapsed := n.Elapsed.Round(time.Second)
+	if n.Err != nil {
+		return msg(MsgNoticeFailed, n.Command, elapsed, n.Err)
+	}
+	return msg(MsgNoticeSucceeded, n.Command, elapsed)
+}
+
+// DesktopNotifier returns a notifier that displays a desktop notification,
+// using notify-send on Linux and BSD systems and osascript on macOS.
+func DesktopNotifier() Notifier {
+	return func(ctx context.Context, n *Notice) error {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			script := fmt.Sprintf("display notification %q with title %q", n.message(), n.Command)
+			cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+		default:
+			cmd = exec.CommandContext(ctx, "notify-send", n.Command, n.message())
+		}
+		return cmd.Run()
+	}
+}
+
+// WebhookNotifier returns a notifier that posts the notice as a JSON object
+// to the URL. Notices are not posted in the offline mode.
+func WebhookNotifier(url string) Notifier {
+	return func(ctx context.Context, n *Notice) error {
+		if Offline(ctx) {
+			return nil
+		}
+		v := map[string]any{
+			"command": n.Command,
+			"elapsed": n.Elapsed.Seconds(),
+			"text":    n.message(),
+		}
+		if n.Err != nil {
+			v["error"] = n.Err.Error()
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return errors.New(resp.Status)
+		}
+		return nil
+	}
+}
+
+// notify delivers the completion notice, if the command ran long enough.
+func (cg *cmdGroup) notify(ctx context.Context, path []string, start time.Time, err error) error {
+	elapsed := time.Since(start)
+	if cg.opts.notifier == nil || elapsed < cg.opts.notifyAfter {
+		return nil
+	}
+	n := &Notice{Command: strings.Join(path, " "), Elapsed: elapsed, Err: err}
+	if err := cg.opts.notifier(ctx, n); err != nil {
+		return fmt.Errorf("%s: %w", msg(MsgNotifyFailed), err)
+	}
+	return nil
+}
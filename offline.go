@@ -0,0 +1,28 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+// ErrOffline is the error returned by the framework subsystems, like the
+// remote command catalogs, that need network access in the offline mode.
+var ErrOffline = errors.New("offline mode")
+
+func withOffline(ctx context.Context, offline bool) context.Context {
+	if offline {
+		return cmdctx.WithOffline(ctx, true)
+	}
+	return ctx
+}
+
+// Offline returns true when the command is run in the offline mode, which is
+// selected with the builtin -offline flag. Commands must avoid network access
+// in the offline mode, so that air-gapped users get predictable behavior.
+func Offline(ctx context.Context) bool {
+	return cmdctx.Offline(ctx)
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"flag"
+	"io"
+	"time"
+)
+
+// Option configures the behavior of a command tree run by the Run function.
+type Option func(*options)
+
+type options struct {
+	posix bool
+
+	rewriter func(args []string) ([]string, error)
+
+	flagListing FlagListing
+
+	wrapWidth int
+
+	noPager bool
+
+	noEcho bool
+
+	notifyAfter time.Duration
+	notifier    Notifier
+
+	stdout, stderr io.Writer
+
+	// builtins is nil when all builtin commands are enabled.
+	builtins []string
+
+	landing *LandingPage
+}
+
+// FlagListing configures how flags are listed in the help output and by the
+// "flags" command.
+type FlagListing struct {
+	// GroupByCategory lists the flags grouped by their FlagInfo.Category
+	// values. Flags without a category are listed first.
+	GroupByCategory bool
+
+	// Less defines the sort order for the flags, which defaults to the
+	// lexicographical order of the flag names.
+	Less func(a, b *flag.Flag) bool
+
+	// Width, when non-zero, lists the flags in two aligned columns with the
+	// first column of the given width, instead of the standard library's
+	// layout.
+	Width int
+
+	// HideDefaults omits the default values of the flags.
+	HideDefaults bool
+
+	// FullDefaults displays the long and multi-line default values in full,
+	// which are otherwise shortened with an ellipsis to keep the listing
+	// aligned. The "flags -verbose" command always displays the full values.
+	FullDefaults bool
+}
+
+// WithPOSIXSyntax makes the command-line parsing follow the POSIX Utility
+// Syntax Guidelines. Flags must be single character names with a single dash
+// prefix, multiple flags can be grouped after one dash (`-abc`), flag values
+// can be attached to their flag (`-ofile`) and `--name=value` syntax is
+// rejected. Flags must precede the arguments and the "--" argument ends the
+// flag processing as usual.
+func WithPOSIXSyntax() Option {
+	return func(opts *options) {
+		opts.posix = true
+	}
+}
+
+// WithFlagListing configures the flag listing in the help output and by the
+// "flags" command.
+func WithFlagListing(l FlagListing) Option {
+	return func(opts *options) {
+		opts.flagListing = l
+	}
+}
+
+// WithArgRewriter sets a function to rewrite the command-line arguments
+// before they are resolved, so that custom conventions (like expanding ticket
+// ids or translating legacy syntax) can be implemented in one place. The
+// Tokenize function can be used to interpret the arguments.
+func WithArgRewriter(rewrite func(args []string) ([]string, error)) Option {
+	return func(opts *options) {
+		opts.rewriter = rewrite
+	}
+}
+
+// WithWrapWidth sets the width for wrapping the help text and flag usage
+// strings when the terminal width cannot be detected, like when the output is
+// redirected. Help output is not wrapped in such cases by default.
+func WithWrapWidth(fallback int) Option {
+	return func(opts *options) {
+		opts.wrapWidth = fallback
+	}
+}
+
+// WithoutPager disables piping the help output through the pager, which is
+// used by default when the help doesn't fit the terminal screen. Setting the
+// PAGER environment variable to "cat" has the same effect.
+func WithoutPager() Option {
+	return func(opts *options) {
+		opts.noPager = true
+	}
+}
+
+// WithoutEquivalentEcho disables printing the scriptable command lines with
+// the EchoEquivalent function.
+func WithoutEquivalentEcho() Option {
+	return func(opts *options) {
+		opts.noEcho = true
+	}
+}
+
+// WithBuiltins enables only the named builtin commands, from "help", "flags",
+// "commands" and "which", so that callers can disable the builtin commands they don't
+// need. Without any names all builtin commands are disabled. The -help and -h
+// flags are only recognized when the "help" command is enabled.
+//
+// Top-level commands with the same names as the builtin commands always
+// replace the builtin commands, so this option is not necessary to provide a
+// custom "help" command.
+func WithBuiltins(names ...string) Option {
+	return func(opts *options) {
+		opts.builtins = append([]string{}, names...)
+	}
+}
+
+// LandingPage configures the top-level help output, which is the first help
+// page seen by the new users.
+type LandingPage struct {
+	// Intro is the introduction paragraphs displayed before the command
+	// listings.
+	Intro string
+
+	// Tasks lists the example invocations for the common tasks, which are
+	// displayed before the command listings.
+	Tasks []Example
+}
+
+// WithLandingPage adds the introduction and the common tasks to the
+// top-level help output. Commands are grouped into separate sections by their
+// categories as usual, so that long command listings are easier to follow.
+// Layout can be changed further with a custom help template, where the values
+// are available as HelpData.Help and HelpData.Tasks fields.
+func WithLandingPage(page LandingPage) Option {
+	return func(opts *options) {
+		opts.landing = &page
+	}
+}
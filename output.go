@@ -0,0 +1,55 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+// WithOutput directs all framework produced output, like the help text,
+// diagnostics and the output of the builtin commands, to the writers instead
+// of the process standard output and standard error, which is useful when
+// the command tree is embedded in a larger program. A nil writer keeps the
+// default.
+func WithOutput(stdout, stderr io.Writer) Option {
+	return func(opts *options) {
+		opts.stdout = stdout
+		opts.stderr = stderr
+	}
+}
+
+func (cg *cmdGroup) stdout() io.Writer {
+	if cg.opts.stdout != nil {
+		return cg.opts.stdout
+	}
+	return os.Stdout
+}
+
+func (cg *cmdGroup) stderr() io.Writer {
+	if cg.opts.stderr != nil {
+		return cg.opts.stderr
+	}
+	return os.Stderr
+}
+
+// getStdout returns the writer for the framework produced output of the
+// current invocation.
+func getStdout(ctx context.Context) io.Writer {
+	return cmdctx.Stdout(ctx)
+}
+
+// getStderr returns the writer for the framework produced diagnostics of the
+// current invocation.
+func getStderr(ctx context.Context) io.Writer {
+	return cmdctx.Stderr(ctx)
+}
+
+// isTerminalWriter returns true if the writer is an interactive terminal.
+func isTerminalWriter(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// writePaged writes the output to the standard output, through the pager
+// from PAGER environment variable (or less) when the standard output is a
+// terminal and the output doesn't fit the screen. Pager is not used when the
+// output is redirected with the WithOutput option. Output is written directly
+// when the pager cannot be started.
+func (cg *cmdGroup) writePaged(ctx context.Context, output []byte) error {
+	term := TerminalInfo(ctx)
+	if cg.opts.noPager || cg.opts.stdout != nil || !term.IsTTY || term.Height == 0 || bytes.Count(output, []byte("\n")) < term.Height {
+		_, err := cg.stdout().Write(output)
+		return err
+	}
+
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = "less"
+	}
+	args := strings.Fields(pager)
+	if len(args) == 0 || args[0] == "cat" {
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(output)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if _, ok := os.LookupEnv("LESS"); !ok {
+		// keep the colors and exit when the output fits the screen
+		cmd.Env = append(os.Environ(), "LESS=FRX")
+	}
+	if err := cmd.Start(); err != nil {
+		_, err := cg.stdout().Write(output)
+		return err
+	}
+	return cmd.Wait()
+}
+
+// pageHelp prints the help through the pager when necessary.
+func (cg *cmdGroup) pageHelp(ctx context.Context, cmdpath []*cmdData) error {
+	var buf bytes.Buffer
+	show := cg.printHelp
+	if cg.helpAll {
+		show = cg.printHelpAll
+	}
+	if err := show(ctx, &buf, cmdpath); err != nil {
+		return err
+	}
+	return cg.writePaged(ctx, buf.Bytes())
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import "sort"
+
+// getOrigin returns the name of the plugin that provides the command, which is
+// collected through the optional `interface{ CommandOrigin() string }` method.
+// It is empty for the core commands.
+func getOrigin(c Command) string {
+	if v, ok := c.(interface{ CommandOrigin() string }); ok {
+		return v.CommandOrigin()
+	}
+	return ""
+}
+
+// HelpPlugin describes the subcommands provided by a plugin in the help
+// output.
+type HelpPlugin struct {
+	Name     string
+	Commands []HelpCommand
+}
+
+// getPluginCommands returns the subcommands provided by the plugins, grouped
+// by the plugin names, in the sorted order.
+func getPluginCommands(cmdpath []*cmdData) []HelpPlugin {
+	var plugins []HelpPlugin
+	for _, g := range groupSubcommands(cmdpath, getOrigin) {
+		plugins = append(plugins, HelpPlugin{Name: g.name, Commands: g.cmds})
+	}
+	return plugins
+}
+
+type commandGroup struct {
+	name string
+	cmds []HelpCommand
+}
+
+// groupSubcommands returns the visible subcommands grouped by the non-empty
+// keys, in the sorted order of the keys and the command names.
+func groupSubcommands(cmdpath []*cmdData, key func(Command) string) []commandGroup {
+	cg, ok := cmdpath[len(cmdpath)-1].cmd.(*cmdGroup)
+	if !ok {
+		return nil
+	}
+
+	var groups []commandGroup
+	index := make(map[string]int)
+	for _, c := range cg.subcmds {
+		k := key(c)
+		if len(k) == 0 || isHidden(c) {
+			continue
+		}
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, commandGroup{name: k})
+		}
+		groups[i].cmds = append(groups[i].cmds, HelpCommand{Name: rootNodes(cmdpath).name(c), Synopsis: getListedSynopsis(c)})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].name < groups[j].name
+	})
+	for _, g := range groups {
+		sort.SliceStable(g.cmds, func(i, j int) bool {
+			return g.cmds[i].Name < g.cmds[j].Name
+		})
+	}
+	return groups
+}
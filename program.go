@@ -0,0 +1,40 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Program holds multiple command trees keyed by their program names, so that
+// multiple tools (e.g. public and internal variants) can be shipped from a
+// single binary through symlinks or hard links.
+type Program map[string][]Command
+
+// Run selects a command tree and runs it with the Run function. Command tree is
+// selected by the `-program` flag when it is the first argument in `args` or
+// by the base name of the running binary otherwise.
+func (p Program) Run(ctx context.Context, args []string, opts ...Option) error {
+	_, name := filepath.Split(os.Args[0])
+	if len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		s := strings.TrimPrefix(strings.TrimPrefix(args[0], "-"), "-")
+		if v, ok := strings.CutPrefix(s, "program="); ok {
+			name, args = v, args[1:]
+		} else if s == "program" {
+			if len(args) < 2 {
+				return errors.New(msg(MsgFlagNeedsArgument, "program"))
+			}
+			name, args = args[1], args[2:]
+		}
+	}
+
+	cmds, ok := p[name]
+	if !ok {
+		return errors.New(msg(MsgProgramNotDefined, name))
+	}
+	return Run(ctx, cmds, args, opts...)
+}
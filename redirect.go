@@ -0,0 +1,71 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"io"
+	"os"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+// redirectValue is the flag.Value for the framework defined -input and
+// -output-file flags.
+type redirectValue string
+
+func (v *redirectValue) String() string {
+	return string(*v)
+}
+
+func (v *redirectValue) Set(s string) error {
+	*v = redirectValue(s)
+	return nil
+}
+
+// AddRedirectFlags defines the standard `-input file` and `-output-file file`
+// flags in the flag set, which redirect the input and the output of the
+// command. Framework opens the input file and exposes it through the
+// cmdctx.Stdin function and writes the output from the cmdctx.Stdout function
+// to the output file atomically, so that the file either has the old contents
+// or the complete output of a successful run. File name "-" selects the
+// standard input or the output. It is typically called from the Command
+// method of the commands that opt into the redirection.
+func AddRedirectFlags(fs *flag.FlagSet) {
+	fs.Var(new(redirectValue), "input", "read the input from `file` instead of the standard input")
+	fs.Var(new(redirectValue), "output-file", "write the output to `file` instead of the standard output")
+}
+
+// lookupRedirect returns the file name from the redirection flag defined with
+// the AddRedirectFlags function, which is empty when the flag is not given or
+// selects the standard input or output.
+func lookupRedirect(fs *flag.FlagSet, name string) string {
+	f := fs.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	if v, ok := f.Value.(*redirectValue); ok && *v != "-" {
+		return string(*v)
+	}
+	return ""
+}
+
+// runRedirected runs the main function of a command with the input and the
+// output redirected as per the redirection flags in the flag set.
+func runRedirected(ctx context.Context, fs *flag.FlagSet, run func(ctx context.Context) error) error {
+	if input := lookupRedirect(fs, "input"); len(input) > 0 {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		ctx = cmdctx.WithInput(ctx, f)
+	}
+	if output := lookupRedirect(fs, "output-file"); len(output) > 0 {
+		return WriteFileAtomic(ctx, output, 0o644, func(w io.Writer) error {
+			return run(cmdctx.WithOutput(ctx, w, nil))
+		})
+	}
+	return run(ctx)
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// WatchConfig returns a channel that receives a value when the file at `path`
+// is modified or the process receives a reload signal (SIGHUP on unix), so
+// that long-running commands can reload their configuration. Notifications
+// are coalesced when the receiver is slow. Returned channel is closed after
+// the context is canceled.
+func WatchConfig(ctx context.Context, path string) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+
+	sigch := make(chan os.Signal, 1)
+	if sigs := reloadSignals(); len(sigs) > 0 {
+		signal.Notify(sigch, sigs...)
+	}
+
+	modTime := func() time.Time {
+		if fi, err := os.Stat(path); err == nil {
+			return fi.ModTime()
+		}
+		return time.Time{}
+	}
+
+	go func() {
+		defer close(ch)
+		defer signal.Stop(sigch)
+
+		last := modTime()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigch:
+				notify()
+			case <-ticker.C:
+				if mtime := modTime(); !mtime.Equal(last) {
+					last = mtime
+					notify()
+				}
+			}
+		}
+	}()
+	return ch
+}
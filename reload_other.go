@@ -0,0 +1,13 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package subcmd
+
+import "os"
+
+// reloadSignals returns nil because there is no conventional reload signal on
+// this platform.
+func reloadSignals() []os.Signal {
+	return nil
+}
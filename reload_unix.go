@@ -0,0 +1,15 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package subcmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals returns the signals that request a configuration reload.
+func reloadSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP}
+}
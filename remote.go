@@ -0,0 +1,108 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+)
+
+// RemoteCommand describes a command provided by a server in a remote command
+// catalog.
+type RemoteCommand struct {
+	Name     string
+	Synopsis string
+	Help     string
+	Flags    []RemoteFlag
+}
+
+// RemoteFlag describes a flag of a remote command.
+type RemoteFlag struct {
+	Name    string
+	Usage   string
+	Default string
+
+	// Bool is true for the flags that do not take a value.
+	Bool bool
+}
+
+// Transport interface defines the requirements for the client-server
+// communication of the remote command catalogs.
+type Transport interface {
+	// Catalog fetches the commands provided by the server.
+	Catalog(ctx context.Context) ([]RemoteCommand, error)
+
+	// Call executes a remote command on the server with the flag values that
+	// differ from the defaults and the command arguments.
+	Call(ctx context.Context, name string, flags map[string]string, args []string) error
+}
+
+// Remote creates a command group with the subcommands populated from a server
+// provided catalog, so that new server-side operations are exposed without a
+// client release. Catalog is fetched through the transport only when the
+// group is selected on the command-line and remote commands are executed
+// through the transport. Remote commands fail with ErrOffline in the offline
+// mode.
+func Remote(name, description string, t Transport) Command {
+	return &cmdGroup{
+		flags:    flag.NewFlagSet(name, flag.ContinueOnError),
+		synopsis: description,
+		remote:   t,
+	}
+}
+
+// fetchRemote populates the subcommands of a remote command group from the
+// remote catalog, once. Catalog is not fetched in the offline mode.
+func (cg *cmdGroup) fetchRemote(ctx context.Context, offline bool) error {
+	if cg.remote == nil || cg.subcmds != nil {
+		return nil
+	}
+	if offline {
+		return ErrOffline
+	}
+	catalog, err := cg.remote.Catalog(ctx)
+	if err != nil {
+		return err
+	}
+	cmds := []Command{}
+	for _, rc := range catalog {
+		cmds = append(cmds, &remoteCmd{spec: rc, transport: cg.remote})
+	}
+	cg.subcmds = cmds
+	return nil
+}
+
+type remoteCmd struct {
+	spec      RemoteCommand
+	transport Transport
+}
+
+func (c *remoteCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet(c.spec.Name, flag.ContinueOnError)
+	for _, f := range c.spec.Flags {
+		if f.Bool {
+			fset.Bool(f.Name, f.Default == "true", f.Usage)
+		} else {
+			fset.String(f.Name, f.Default, f.Usage)
+		}
+	}
+	return fset, func(ctx context.Context, args []string) error {
+		if Offline(ctx) {
+			return ErrOffline
+		}
+		flags := make(map[string]string)
+		fset.VisitAll(func(f *flag.Flag) {
+			if v := f.Value.String(); v != f.DefValue {
+				flags[f.Name] = v
+			}
+		})
+		return c.transport.Call(ctx, c.spec.Name, flags, args)
+	}
+}
+
+func (c *remoteCmd) CommandHelp() string {
+	if len(c.spec.Help) > 0 {
+		return c.spec.Help
+	}
+	return c.spec.Synopsis
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+// ResourceOps interface defines the standard operations on a resource type,
+// which are turned into the subcommands of a resource command group.
+type ResourceOps interface {
+	Get(ctx context.Context, args []string) error
+	List(ctx context.Context, args []string) error
+	Create(ctx context.Context, args []string) error
+	Update(ctx context.Context, args []string) error
+	Delete(ctx context.Context, args []string) error
+}
+
+// ResourceFlags is an optional interface for ResourceOps objects that need
+// command-line flags for the operations. The operation name is one of "get",
+// "list", "create", "update" or "delete".
+type ResourceFlags interface {
+	ResourceFlags(op string, fs *flag.FlagSet)
+}
+
+// Resource creates a command group with the standard get, list, create, update
+// and delete subcommands for a resource.
+func Resource(name string, ops ResourceOps) Command {
+	newOp := func(op, synopsis string, mainf MainFunc) Command {
+		fs := flag.NewFlagSet(op, flag.ContinueOnError)
+		if v, ok := ops.(ResourceFlags); ok {
+			v.ResourceFlags(op, fs)
+		}
+		return &resourceCmd{
+			fset:     fs,
+			mainf:    mainf,
+			synopsis: synopsis,
+		}
+	}
+	return Group(name, fmt.Sprintf("Manage %s resources.", name),
+		newOp("get", fmt.Sprintf("Prints %s details.", name), ops.Get),
+		newOp("list", fmt.Sprintf("Lists %s resources.", name), ops.List),
+		newOp("create", fmt.Sprintf("Creates %s resources.", name), ops.Create),
+		newOp("update", fmt.Sprintf("Updates %s resources.", name), ops.Update),
+		newOp("delete", fmt.Sprintf("Deletes %s resources.", name), ops.Delete),
+	)
+}
+
+type resourceCmd struct {
+	fset     *flag.FlagSet
+	mainf    MainFunc
+	synopsis string
+}
+
+func (c *resourceCmd) Command() (*flag.FlagSet, MainFunc) {
+	return c.fset, c.mainf
+}
+
+func (c *resourceCmd) CommandHelp() string {
+	return c.synopsis
+}
@@ -3,10 +3,25 @@
 package subcmd
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/bvkgo/subcmd/cmdctx"
 )
 
 type TestCmd struct {
@@ -104,3 +119,1585 @@ func TestRun(t *testing.T) {
 		}
 	}
 }
+
+func TestParseError(t *testing.T) {
+	ctx := context.Background()
+
+	scan := newTestCmd("scan")
+	scan.flags.Int("limit", 0, "max number of items")
+	db := Group("db", "manage database", scan)
+	cmds := []Command{db}
+
+	{
+		args := []string{"db", "scan", "-limit", "ten"}
+		var perr *ParseError
+		if err := Run(ctx, cmds, args); !errors.As(err, &perr) {
+			t.Fatalf("want ParseError, got %v", err)
+		}
+		if perr.Index != 2 || perr.Token != "-limit" {
+			t.Fatalf("want `-limit` at 2, got %q at %d", perr.Token, perr.Index)
+		}
+		if len(perr.Path) != 3 || perr.Path[1] != "db" || perr.Path[2] != "scan" {
+			t.Fatalf("want [* db scan] path, got %v", perr.Path)
+		}
+	}
+
+	{
+		args := []string{"db", "get"}
+		var perr *ParseError
+		if err := Run(ctx, cmds, args); !errors.As(err, &perr) {
+			t.Fatalf("want ParseError, got %v", err)
+		}
+		if perr.Index != 1 || perr.Token != "get" {
+			t.Fatalf("want `get` at 1, got %q at %d", perr.Token, perr.Index)
+		}
+		if want := strings.Join(perr.Path, " ") + ": "; !strings.HasPrefix(perr.Error(), want) || !strings.HasSuffix(want, " db: ") {
+			t.Fatalf("want error prefixed with the command path, got %q", perr.Error())
+		}
+	}
+}
+
+func TestPrintCommand(t *testing.T) {
+	ctx := context.Background()
+
+	scan := newTestCmd("scan")
+	scan.flags.Int("limit", 0, "max number of items")
+	scan.flags.Var(new(secretValue), "token", "access token")
+	cmds := []Command{Group("db", "manage database", scan)}
+
+	var stdout bytes.Buffer
+	args := []string{"db", "scan", "-print-command", "-limit", "10", "-token", "abc123", "prefix"}
+	if err := Run(ctx, cmds, args, WithOutput(&stdout, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if scan.args != nil {
+		t.Fatalf("want command not to run, got args %v", scan.args)
+	}
+	for _, want := range []string{"Command: ", " db scan\n", "Args: \"prefix\"\n", "\t-limit=10\n", "\t-token=<redacted>\n"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("want %q in the output, got %q", want, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "abc123") {
+		t.Fatalf("want secret flag value redacted, got %q", stdout.String())
+	}
+
+	args = []string{"db", "scan", "-print-command=false", "prefix"}
+	if err := Run(ctx, cmds, args); err != nil || len(scan.args) != 1 {
+		t.Fatalf("want command to run with -print-command=false, got %v with args %v", err, scan.args)
+	}
+	if err := Run(ctx, cmds, []string{"db", "scan", "-print-command=maybe"}); err == nil {
+		t.Fatalf("want error for an invalid -print-command value")
+	}
+}
+
+func TestOptionalFlag(t *testing.T) {
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		args  []string
+		state OptionalState
+		value string
+	}{
+		{[]string{"show"}, OptionalUnset, ""},
+		{[]string{"show", "-color"}, OptionalNoValue, "auto"},
+		{[]string{"show", "-color=true"}, OptionalWithValue, "true"},
+		{[]string{"show", "-color=never", "arg"}, OptionalWithValue, "never"},
+	} {
+		show := newTestCmd("show")
+		color := OptionalFlag(show.flags, "color", "auto", "colorize the output")
+		if err := Run(ctx, []Command{show}, test.args); err != nil {
+			t.Fatal(err)
+		}
+		if color.State() != test.state || color.Value() != test.value {
+			t.Fatalf("%v: want %d/%q, got %d/%q", test.args, test.state, test.value, color.State(), color.Value())
+		}
+	}
+
+	// values from the environment are explicit values
+	t.Setenv("SUBCMD_TEST_COLOR", "true")
+	show := newTestCmd("show")
+	color := OptionalFlag(show.flags, "color", "auto", "colorize the output")
+	SetFlagInfo(show.flags, "color", FlagInfo{Env: "SUBCMD_TEST_COLOR"})
+	if err := Run(ctx, []Command{show}, []string{"show"}); err != nil {
+		t.Fatal(err)
+	}
+	if color.State() != OptionalWithValue || color.Value() != "true" {
+		t.Fatalf("want explicit value from the environment, got %d/%q", color.State(), color.Value())
+	}
+}
+
+func TestSeed(t *testing.T) {
+	ctx := context.Background()
+
+	var values []int64
+	gen := New("gen", "Generates a random number.", func(ctx context.Context, args []string) error {
+		values = append(values, Rand(ctx).Int63())
+		return nil
+	})
+	for i := 0; i < 2; i++ {
+		if err := Run(ctx, []Command{gen}, []string{"-seed", "42", "gen"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if values[0] != values[1] {
+		t.Fatalf("want same random numbers with same seed, got %v", values)
+	}
+}
+
+func TestMovedCommand(t *testing.T) {
+	ctx := context.Background()
+
+	create := newTestCmd("create")
+	full := create.flags.Bool("full", false, "take a full backup")
+	cmds := []Command{
+		Group("db", "manage database", MovedCommand("db backup", "backup create")),
+		Group("backup", "manage backups", create),
+	}
+
+	args := []string{"db", "backup", "-full", "backup-argument"}
+	if err := Run(ctx, cmds, args); err != nil {
+		t.Fatal(err)
+	}
+	if len(create.args) != 1 || create.args[0] != "backup-argument" {
+		t.Fatalf("want `backup-argument`, got %v", create.args)
+	}
+	if *full == false {
+		t.Fatalf("want true, got false")
+	}
+}
+
+func TestPOSIXSyntax(t *testing.T) {
+	ctx := context.Background()
+
+	tar := newTestCmd("tar")
+	create := tar.flags.Bool("c", false, "create an archive")
+	verbose := tar.flags.Bool("v", false, "verbose output")
+	file := tar.flags.String("f", "", "archive file")
+	cmds := []Command{tar}
+
+	args := []string{"tar", "-cvf", "out.tar", "dir"}
+	if err := Run(ctx, cmds, args, WithPOSIXSyntax()); err != nil {
+		t.Fatal(err)
+	}
+	if !*create || !*verbose || *file != "out.tar" {
+		t.Fatalf("want -c -v -f out.tar, got %v %v %q", *create, *verbose, *file)
+	}
+	if len(tar.args) != 1 || tar.args[0] != "dir" {
+		t.Fatalf("want `dir`, got %v", tar.args)
+	}
+
+	if err := Run(ctx, cmds, []string{"tar", "-fother.tar"}, WithPOSIXSyntax()); err != nil {
+		t.Fatal(err)
+	}
+	if *file != "other.tar" {
+		t.Fatalf("want other.tar, got %q", *file)
+	}
+
+	if err := Run(ctx, cmds, []string{"tar", "--file=x"}, WithPOSIXSyntax()); err == nil {
+		t.Fatalf("want error for long flag syntax, got nil")
+	}
+}
+
+func TestWarningsAsErrors(t *testing.T) {
+	ctx := context.Background()
+
+	check := New("check", "Checks the input.", func(ctx context.Context, args []string) error {
+		Warn(ctx, "input %q looks suspicious", "x")
+		return nil
+	})
+	cmds := []Command{check}
+
+	if err := Run(ctx, cmds, []string{"check"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(ctx, cmds, []string{"-warnings-as-errors", "check"}); err == nil {
+		t.Fatalf("want error with -warnings-as-errors, got nil")
+	}
+
+	// moved commands run the new command with the same switches
+	create := newTestCmd("create")
+	cmds = []Command{
+		Group("db", "manage database", MovedCommand("db backup", "backup create")),
+		Group("backup", "manage backups", create),
+	}
+	args := []string{"-warnings-as-errors", "db", "backup", "backup-argument"}
+	if err := Run(ctx, cmds, args, WithOutput(io.Discard, io.Discard)); err == nil {
+		t.Fatalf("want error with -warnings-as-errors for a moved command, got nil")
+	}
+	if len(create.args) != 1 || create.args[0] != "backup-argument" {
+		t.Fatalf("want `backup-argument`, got %v", create.args)
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	ctx := context.Background()
+
+	build := newTestCmd("build")
+	push := newTestCmd("push")
+	deploy := New("deploy", "Builds and pushes.", func(ctx context.Context, args []string) error {
+		if err := Invoke(ctx, []string{"build"}, args...); err != nil {
+			return err
+		}
+		return Invoke(ctx, []string{"image", "push"}, args...)
+	})
+	cmds := []Command{build, Group("image", "manage images", push), deploy}
+
+	if err := Run(ctx, cmds, []string{"deploy", "target"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(build.args) != 1 || len(push.args) != 1 || push.args[0] != "target" {
+		t.Fatalf("want `target` for build and push, got %v and %v", build.args, push.args)
+	}
+}
+
+func TestInvokeOutput(t *testing.T) {
+	ctx := context.Background()
+
+	var output []byte
+	hello := New("hello", "Prints a greeting.", func(ctx context.Context, args []string) error {
+		fmt.Fprintln(cmdctx.Stdout(ctx), "hello", args[0])
+		return nil
+	})
+	shout := New("shout", "Prints a greeting loudly.", func(ctx context.Context, args []string) error {
+		data, err := InvokeOutput(ctx, []string{"hello"}, args...)
+		output = data
+		return err
+	})
+
+	if err := Run(ctx, []Command{hello, shout}, []string{"shout", "world"}); err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "hello world\n" {
+		t.Fatalf("want `hello world`, got %q", output)
+	}
+
+	var stdout bytes.Buffer
+	if err := Run(ctx, []Command{hello, shout}, []string{"shout", "world"}, WithOutput(&stdout, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "hello world\n" || stdout.Len() != 0 {
+		t.Fatalf("want captured output only, got %q and %q", output, stdout.String())
+	}
+}
+
+func TestMovedCommandCycle(t *testing.T) {
+	ctx := context.Background()
+
+	cmds := []Command{
+		Group("db", "manage database", MovedCommand("db backup", "backup create")),
+		Group("backup", "manage backups", MovedCommand("backup create", "db backup")),
+	}
+
+	var cerr *CycleError
+	if err := Validate(cmds); !errors.As(err, &cerr) {
+		t.Fatalf("want CycleError from Validate, got %v", err)
+	}
+	if err := Run(ctx, cmds, []string{"db", "backup"}); !errors.As(err, &cerr) {
+		t.Fatalf("want CycleError from Run, got %v", err)
+	}
+	if len(cerr.Chain) != 3 || cerr.Chain[0] != "db backup" || cerr.Chain[2] != "db backup" {
+		t.Fatalf("want [db backup, backup create, db backup] chain, got %v", cerr.Chain)
+	}
+}
+
+func TestMovedCommandPath(t *testing.T) {
+	create := newTestCmd("create")
+	cmds := []Command{
+		Group("db", "manage database", MovedCommand("db backup", "backup create")),
+		Group("backup", "manage backups", create),
+	}
+	if err := Validate(cmds); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds[0] = Group("database", "manage database", MovedCommand("db backup", "backup create"))
+	if err := Validate(cmds); err == nil {
+		t.Fatalf("want error for a moved command stub at the wrong path")
+	}
+}
+
+func TestMovedCommandEmptyPath(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("want panic for an empty moved command path")
+		}
+	}()
+	MovedCommand("", "backup create")
+}
+
+func TestShortcuts(t *testing.T) {
+	ctx := context.Background()
+
+	scan := newTestCmd("scan")
+	limit := scan.flags.Int("limit", 0, "max number of items")
+	file := filepath.Join(t.TempDir(), "shortcuts.json")
+	cmds := []Command{Group("db", "manage database", scan), Shortcuts(file)}
+
+	if err := Run(ctx, cmds, []string{"shortcut", "save", "recent", "--", "db", "scan", "-limit", "10"}); err != nil {
+		t.Fatal(err)
+	}
+	if *limit != 0 {
+		t.Fatalf("want flags unchanged by the shortcut save, got -limit %d", *limit)
+	}
+	if err := Run(ctx, cmds, []string{"shortcut", "save", "bad", "--", "db", "get"}); err == nil {
+		t.Fatalf("want error for invalid shortcut, got nil")
+	}
+	if err := Run(ctx, cmds, []string{"shortcut", "save", "help", "--", "db", "scan"}); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("want ErrExist for a builtin command name, got %v", err)
+	}
+
+	if err := Run(ctx, cmds, []string{"recent", "jobs/"}); err != nil {
+		t.Fatal(err)
+	}
+	if *limit != 10 || len(scan.args) != 1 || scan.args[0] != "jobs/" {
+		t.Fatalf("want -limit 10 with `jobs/`, got %d with %v", *limit, scan.args)
+	}
+}
+
+func TestArgRewriter(t *testing.T) {
+	ctx := context.Background()
+
+	show := newTestCmd("show")
+	verbose := show.flags.Bool("verbose", false, "verbose output")
+	cmds := []Command{Group("ticket", "manage tickets", show)}
+
+	// translate the legacy `/v` flag syntax
+	rewrite := func(args []string) ([]string, error) {
+		var result []string
+		for _, t := range Tokenize(args) {
+			if !t.IsFlag && t.Arg == "/v" {
+				result = append(result, "-verbose")
+				continue
+			}
+			result = append(result, t.Arg)
+		}
+		return result, nil
+	}
+
+	args := []string{"ticket", "show", "/v", "T-1"}
+	if err := Run(ctx, cmds, args, WithArgRewriter(rewrite)); err != nil {
+		t.Fatal(err)
+	}
+	if !*verbose || len(show.args) != 1 || show.args[0] != "T-1" {
+		t.Fatalf("want -verbose with `T-1`, got %v with %v", *verbose, show.args)
+	}
+}
+
+func TestNoArgs(t *testing.T) {
+	ctx := context.Background()
+
+	ran := false
+	pause := New("pause", "Pauses the job.", NoArgs(func(ctx context.Context, args []string) error {
+		ran = true
+		return nil
+	}))
+	cmds := []Command{Group("job", "manage single job", pause)}
+
+	if err := Run(ctx, cmds, []string{"job", "pause", "all"}); err == nil || ran {
+		t.Fatalf("want error for unexpected argument, got %v", err)
+	}
+	if err := Run(ctx, cmds, []string{"job", "pause"}); err != nil || !ran {
+		t.Fatalf("want command to run, got %v", err)
+	}
+}
+
+type templateCmd struct {
+	TestCmd
+}
+
+func (t *templateCmd) CommandHelpTemplate() string {
+	return "{{.Usage}}\n{{range .Path}}[{{.}}]{{end}}\n"
+}
+
+func TestHelpTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	show := &templateCmd{TestCmd: *newTestCmd("show")}
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{show}}
+	cmdseq, _, err := cg.resolve(ctx, []string{"show"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := cg.printHelp(ctx, &sb, cmdseq); err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf("%s\n", getUsage(cmdseq))
+	want += fmt.Sprintf("[%s][show]\n", getPath(cmdseq)[0])
+	if sb.String() != want {
+		t.Fatalf("want %q, got %q", want, sb.String())
+	}
+}
+
+func TestColorHelp(t *testing.T) {
+	ctx := context.Background()
+
+	show := newTestCmd("show")
+	show.flags.Int("limit", 0, "max number of items")
+	for _, mode := range []string{"always", "never"} {
+		cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{show}}
+		cmdseq, _, err := cg.resolve(ctx, []string{"-color=" + mode, "show", "-h"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var sb strings.Builder
+		if err := cg.printHelp(ctx, &sb, cmdseq); err != nil {
+			t.Fatal(err)
+		}
+		if colored := strings.Contains(sb.String(), ansiYellow+"-limit"+ansiReset); colored != (mode == "always") {
+			t.Fatalf("with -color=%s: got %q", mode, sb.String())
+		}
+	}
+
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{show}}
+	if _, _, err := cg.resolve(ctx, []string{"-color=blue", "show"}); err == nil {
+		t.Fatalf("want error for invalid color mode")
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	ctx := context.Background()
+
+	RegisterErrorCode("E1042", "Database is locked.\nStop the other instance and retry.")
+	err := Coded("E1042", errors.New("locked"))
+	if cerr := new(CodedError); !errors.As(err, &cerr) || cerr.Code != "E1042" {
+		t.Fatalf("want coded error E1042, got %v", err)
+	}
+
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{newTestCmd("run")}}
+	_, args, err := cg.resolve(ctx, []string{"help", "errors", "E1042"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cg.specialCmd != "errors" || len(args) != 1 || args[0] != "E1042" {
+		t.Fatalf("want error catalog for E1042, got %q with %q", cg.specialCmd, args)
+	}
+
+	var sb strings.Builder
+	if err := printErrorCodes(&sb, args); err != nil {
+		t.Fatal(err)
+	}
+	if want := "E1042\n\nDatabase is locked.\nStop the other instance and retry.\n"; sb.String() != want {
+		t.Fatalf("want %q, got %q", want, sb.String())
+	}
+	if err := printErrorCodes(&sb, []string{"E0"}); err == nil {
+		t.Fatalf("want error for undefined error code")
+	}
+}
+
+type exampleCmd struct {
+	TestCmd
+}
+
+func (e *exampleCmd) CommandExamples() []Example {
+	return []Example{
+		{Description: "Show the first item.", Command: "tool show 1"},
+		{Command: "tool show -all"},
+	}
+}
+
+func TestExamples(t *testing.T) {
+	ctx := context.Background()
+
+	show := &exampleCmd{TestCmd: *newTestCmd("show")}
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{show}}
+	cmdseq, _, err := cg.resolve(ctx, []string{"-color=never", "show"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := cg.printHelp(ctx, &sb, cmdseq); err != nil {
+		t.Fatal(err)
+	}
+	want := "\n\nExamples:\n\t# Show the first item.\n\t$ tool show 1\n\n\t$ tool show -all\n"
+	if !strings.HasSuffix(sb.String(), want) {
+		t.Fatalf("want suffix %q, got %q", want, sb.String())
+	}
+}
+
+type pluginCmd struct {
+	TestCmd
+}
+
+func (p *pluginCmd) CommandOrigin() string {
+	return "acme"
+}
+
+func TestPluginCommands(t *testing.T) {
+	ctx := context.Background()
+
+	deploy := &pluginCmd{TestCmd: *newTestCmd("deploy")}
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{newTestCmd("run"), deploy}}
+	cmdseq, _, err := cg.resolve(ctx, []string{"-color=never", "help"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := cg.printHelp(ctx, &sb, cmdseq); err != nil {
+		t.Fatal(err)
+	}
+	want := "\trun              First line of help output is used as synopsis.\n\n" +
+		"Commands provided by plugin acme:\n" +
+		"\tdeploy           First line of help output is used as synopsis.\n"
+	if !strings.Contains(sb.String(), want) {
+		t.Fatalf("want %q in the help, got %q", want, sb.String())
+	}
+	if spec := NewSpec("tool", cg.subcmds); spec.Commands[0].Plugin != "acme" {
+		t.Fatalf("want plugin name in the spec, got %q", spec.Commands[0].Plugin)
+	}
+}
+
+func TestSetLocale(t *testing.T) {
+	ctx := context.Background()
+	defer SetFormatter(Catalog(nil))
+
+	AddLocale("pt", Catalog{
+		MsgHelpUsage:       {One: "Uso:"},
+		MsgHelpCmdSynopsis: {One: "Descreve comandos e opções"},
+	})
+	if SetLocale("xx_YY") {
+		t.Fatalf("want no formatter for an unknown locale")
+	}
+	if !SetLocale("pt_BR.UTF-8") {
+		t.Fatalf("want the pt formatter for pt_BR.UTF-8 locale")
+	}
+
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{newTestCmd("run")}}
+	cmdseq, _, err := cg.resolve(ctx, []string{"-color=never", "help"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := cg.printHelp(ctx, &sb, cmdseq); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Uso: ", "Descreve comandos e opções", "Subcommands:"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Fatalf("want %q in the help, got %q", want, sb.String())
+		}
+	}
+}
+
+type fakeTransport struct {
+	fetches int
+	name    string
+	flags   map[string]string
+	args    []string
+}
+
+func (f *fakeTransport) Catalog(ctx context.Context) ([]RemoteCommand, error) {
+	f.fetches++
+	return []RemoteCommand{{
+		Name:     "restart",
+		Synopsis: "Restarts a server.",
+		Flags: []RemoteFlag{
+			{Name: "force", Usage: "skip the health checks", Bool: true},
+			{Name: "zone", Usage: "zone of the servers", Default: "us"},
+		},
+	}}, nil
+}
+
+func (f *fakeTransport) Call(ctx context.Context, name string, flags map[string]string, args []string) error {
+	f.name, f.flags, f.args = name, flags, args
+	return nil
+}
+
+func TestRemote(t *testing.T) {
+	ctx := context.Background()
+
+	ft := new(fakeTransport)
+	cmds := []Command{newTestCmd("run"), Remote("ops", "Server operations.", ft)}
+	if err := Run(ctx, cmds, []string{"run"}); err != nil {
+		t.Fatal(err)
+	}
+	if ft.fetches != 0 {
+		t.Fatalf("want no catalog fetch for other commands, got %d", ft.fetches)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := Run(ctx, cmds, []string{"ops", "restart", "-force", "web-1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ft.fetches != 1 {
+		t.Fatalf("want one catalog fetch, got %d", ft.fetches)
+	}
+	if ft.name != "restart" || len(ft.flags) != 1 || ft.flags["force"] != "true" || len(ft.args) != 1 {
+		t.Fatalf("want restart with -force for web-1, got %q with %v and %q", ft.name, ft.flags, ft.args)
+	}
+}
+
+func TestOffline(t *testing.T) {
+	ctx := context.Background()
+
+	var offline bool
+	check := New("check", "Checks the offline mode.", func(ctx context.Context, args []string) error {
+		offline = Offline(ctx)
+		return nil
+	})
+	ft := new(fakeTransport)
+	cmds := []Command{check, Remote("ops", "Server operations.", ft)}
+	if err := Run(ctx, cmds, []string{"-offline", "check"}); err != nil {
+		t.Fatal(err)
+	}
+	if !offline {
+		t.Fatalf("want offline mode with -offline flag")
+	}
+	if err := Run(ctx, cmds, []string{"-offline", "ops", "restart"}); !errors.Is(err, ErrOffline) || ft.fetches != 0 {
+		t.Fatalf("want ErrOffline without a catalog fetch, got %v after %d fetches", err, ft.fetches)
+	}
+}
+
+func TestPager(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "paged")
+	t.Setenv("PAGER", "tee "+file)
+
+	ctx := WithTerminal(context.Background(), &Terminal{IsTTY: true, Height: 2})
+	cg := &cmdGroup{flags: flag.CommandLine}
+	if err := cg.writePaged(ctx, []byte("one\ntwo\nthree\n")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(file); err != nil || string(data) != "one\ntwo\nthree\n" {
+		t.Fatalf("want the output through the pager, got %q (%v)", data, err)
+	}
+
+	cg.opts.noPager = true
+	os.Remove(file)
+	if err := cg.writePaged(ctx, []byte("one\ntwo\nthree\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("want no pager with the pager disabled, got %v", err)
+	}
+}
+
+func TestLicenses(t *testing.T) {
+	ctx := context.Background()
+
+	fsys := fstest.MapFS{
+		"licenses/example.com/a/LICENSE": {Data: []byte("License A\n")},
+		"licenses/example.com/b/LICENSE": {Data: []byte("License B\n")},
+	}
+	var got []byte
+	show := New("show", "Shows the licenses.", func(ctx context.Context, args []string) error {
+		data, err := InvokeOutput(ctx, []string{"licenses"}, args...)
+		got = data
+		return err
+	})
+	if err := Run(ctx, []Command{show, Licenses(fsys)}, []string{"show", "licenses/example.com/b"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "==> licenses/example.com/b/LICENSE <==\n\nLicense B\n"; string(got) != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+	if err := Run(ctx, []Command{Licenses(fsys)}, []string{"licenses", "missing"}); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want ErrNotExist for unmatched prefix, got %v", err)
+	}
+}
+
+type categoryCmd struct {
+	TestCmd
+	category string
+}
+
+func (c *categoryCmd) CommandCategory() string {
+	return c.category
+}
+
+func TestCategories(t *testing.T) {
+	ctx := context.Background()
+
+	trace := &categoryCmd{TestCmd: *newTestCmd("trace"), category: "Debugging"}
+	dump := &categoryCmd{TestCmd: *newTestCmd("dump"), category: "Debugging"}
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{trace, newTestCmd("run"), dump}}
+	cmdseq, _, err := cg.resolve(ctx, []string{"-color=never", "help"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sb strings.Builder
+	if err := cg.printHelp(ctx, &sb, cmdseq); err != nil {
+		t.Fatal(err)
+	}
+	want := "\trun              First line of help output is used as synopsis.\n\n" +
+		"Debugging:\n" +
+		"\tdump             First line of help output is used as synopsis.\n" +
+		"\ttrace            First line of help output is used as synopsis.\n"
+	if !strings.Contains(sb.String(), want) {
+		t.Fatalf("want %q in the help, got %q", want, sb.String())
+	}
+}
+
+func TestHidden(t *testing.T) {
+	ctx := context.Background()
+
+	debug := newTestCmd("debug")
+	cmds := []Command{newTestCmd("run"), Hidden(debug), Hidden(Group("internal", "Internal commands.", newTestCmd("dump")))}
+	if err := Run(ctx, cmds, []string{"debug", "now"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(debug.args) != 1 || debug.args[0] != "now" {
+		t.Fatalf("want hidden command to run with `now`, got %v", debug.args)
+	}
+
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: cmds}
+	cmdseq, _, err := cg.resolve(ctx, []string{"help"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, sub := range getSubcommands(cmdseq) {
+		if sub[0] == "debug" || sub[0] == "internal" {
+			t.Fatalf("want hidden commands not listed, got %q", sub[0])
+		}
+	}
+}
+
+func TestWizard(t *testing.T) {
+	ctx := withPath(context.Background(), []string{"tool", "init"})
+
+	fset := flag.NewFlagSet("init", flag.ContinueOnError)
+	name := fset.String("name", "", "project name")
+	region := fset.String("region", "us", "deployment region")
+	owner := fset.String("owner", "", "project owner")
+	steps := []WizardStep{
+		{Flag: "name", Prompt: "Project name", Validate: func(s string) error {
+			if len(s) == 0 {
+				return errors.New("name is required")
+			}
+			return nil
+		}},
+		{Flag: "region", Prompt: "Region"},
+		{Flag: "owner", Prompt: "Owner", Optional: true},
+	}
+
+	// empty name is rejected, going back from region re-prompts for the name,
+	// region keeps the default and the owner is skipped
+	input := strings.NewReader("\nfirst\n<\nmy app\n\n-\ny\n")
+	var sb strings.Builder
+	if err := runWizard(ctx, input, &sb, fset, steps); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "my app" || *region != "us" || *owner != "" {
+		t.Fatalf("want name `my app` in region us, got %q in %q by %q", *name, *region, *owner)
+	}
+	if want := "\ttool init -name='my app' -region=us\n"; !strings.Contains(sb.String(), want) {
+		t.Fatalf("want %q in the output, got %q", want, sb.String())
+	}
+
+	*name = ""
+	if err := runWizard(ctx, strings.NewReader("other\n\n-\nn\n"), &sb, fset, steps); err == nil || *name != "" {
+		t.Fatalf("want canceled wizard to leave the flags unchanged, got %v with %q", err, *name)
+	}
+}
+
+type argsUsageCmd struct {
+	TestCmd
+}
+
+func (u *argsUsageCmd) CommandUsage() string {
+	return "<key> <value>"
+}
+
+func TestCommandUsage(t *testing.T) {
+	ctx := context.Background()
+
+	set := &argsUsageCmd{TestCmd: *newTestCmd("set")}
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{Group("db", "Database commands.", set)}}
+	cmdseq, _, err := cg.resolve(ctx, []string{"db", "set"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage := getUsage(cmdseq); !strings.HasSuffix(usage, " db set <flags> <key> <value>") {
+		t.Fatalf("want argument placeholders in the usage, got %q", usage)
+	}
+}
+
+func TestEquivalentCommand(t *testing.T) {
+	ctx := withPath(context.Background(), []string{"tool", "db", "set"})
+
+	fset := flag.NewFlagSet("set", flag.ContinueOnError)
+	fset.Duration("ttl", 0, "time to live")
+	fset.Bool("force", false, "overwrite the value")
+	fset.String("region", "us", "region of the database")
+	fset.Set("ttl", "5m")
+	fset.Set("force", "true")
+
+	want := "tool db set -force -ttl=5m0s key 'some value'"
+	if got := equivalentCommand(ctx, fset, []string{"key", "some value"}); got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+}
+
+func TestHelpAll(t *testing.T) {
+	ctx := context.Background()
+
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{
+		Group("db", "Database commands.", newTestCmd("scan"), Hidden(newTestCmd("debug"))),
+		newTestCmd("run"),
+	}}
+	cmdseq, _, err := cg.resolve(ctx, []string{"-color=never", "help", "-all"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cg.helpAll {
+		t.Fatalf("want -all flag for the help command")
+	}
+
+	var sb strings.Builder
+	if err := cg.printHelpAll(ctx, &sb, cmdseq); err != nil {
+		t.Fatal(err)
+	}
+	var usages []string
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if strings.HasPrefix(line, "Usage: ") {
+			fields := strings.Fields(line)
+			usages = append(usages, strings.Join(fields[2:len(fields)-1], " "))
+		}
+	}
+	want := []string{"<flags> <subcommand>", "db <flags> <subcommand>", "db scan <flags>", "run <flags>"}
+	if strings.Join(usages, ",") != strings.Join(want, ",") {
+		t.Fatalf("want usages %q, got %q", want, usages)
+	}
+}
+
+func TestSpecCommand(t *testing.T) {
+	ctx := context.Background()
+
+	var spec *Spec
+	export := New("export", "Exports the spec.", func(ctx context.Context, args []string) error {
+		data, err := InvokeOutput(ctx, []string{"__spec"})
+		if err != nil {
+			return err
+		}
+		spec, err = ReadSpec(bytes.NewReader(data))
+		return err
+	})
+	set := &argsUsageCmd{TestCmd: *newTestCmd("set")}
+	if err := Run(ctx, []Command{export, Group("db", "Database commands.", set)}, []string{"export"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, c := range spec.Commands {
+		paths = append(paths, fmt.Sprintf("%s:%t:%s", c.Path, c.Group, c.Usage))
+	}
+	if want := "db:true:,db set:false:<key> <value>,export:false:<args>"; strings.Join(paths, ",") != want {
+		t.Fatalf("want %q, got %q", want, strings.Join(paths, ","))
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	ctx := context.Background()
+
+	var notices []*Notice
+	notifier := func(ctx context.Context, n *Notice) error {
+		notices = append(notices, n)
+		return nil
+	}
+	failed := errors.New("failed")
+	backup := New("backup", "Runs a backup.", func(ctx context.Context, args []string) error {
+		if len(args) > 0 {
+			return failed
+		}
+		return nil
+	})
+	if err := Run(ctx, []Command{backup}, []string{"backup"}, WithNotifications(time.Hour, notifier)); err != nil {
+		t.Fatal(err)
+	}
+	if len(notices) != 0 {
+		t.Fatalf("want no notices for short commands, got %d", len(notices))
+	}
+	if err := Run(ctx, []Command{backup}, []string{"backup", "fail"}, WithNotifications(0, notifier)); !errors.Is(err, failed) {
+		t.Fatal(err)
+	}
+	if len(notices) != 1 || !strings.HasSuffix(notices[0].Command, " backup") || notices[0].Err != failed {
+		t.Fatalf("want a failure notice for backup, got %v", notices)
+	}
+}
+
+func TestWithOutput(t *testing.T) {
+	ctx := context.Background()
+
+	var stdout, stderr bytes.Buffer
+	cmds := []Command{newTestCmd("run")}
+	if err := Run(ctx, cmds, []string{"help", "run"}, WithOutput(&stdout, &stderr)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stdout.String(), "Usage: ") || strings.Contains(stdout.String(), "\x1b[") {
+		t.Fatalf("want plain help output in the writer, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	if err := Run(ctx, cmds, []string{"flags"}, WithOutput(&stdout, &stderr)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "-test.run") {
+		t.Fatalf("want flags listing in the writer, got %q", stdout.String())
+	}
+}
+
+func TestCmdctx(t *testing.T) {
+	ctx := context.Background()
+
+	var path []string
+	var fset *flag.FlagSet
+	var stdout, stderr io.Writer
+	var offline bool
+	get := New("get", "Prints a value.", func(ctx context.Context, args []string) error {
+		path, fset, offline = cmdctx.Path(ctx), cmdctx.Flags(ctx), cmdctx.Offline(ctx)
+		stdout, stderr = cmdctx.Stdout(ctx), cmdctx.Stderr(ctx)
+		return nil
+	})
+	cmds := []Command{Group("db", "Manages the database.", get)}
+
+	var outbuf, errbuf bytes.Buffer
+	if err := Run(ctx, cmds, []string{"-offline", "db", "get"}, WithOutput(&outbuf, &errbuf)); err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 3 || path[1] != "db" || path[2] != "get" {
+		t.Fatalf("want the db get command path, got %q", path)
+	}
+	if fset == nil || fset.Name() != "get" {
+		t.Fatalf("want the flags of the get command, got %v", fset)
+	}
+	if stdout != &outbuf || stderr != &errbuf {
+		t.Fatalf("want the writers from the WithOutput option")
+	}
+	if !offline {
+		t.Fatalf("want the offline mode from the -offline flag")
+	}
+}
+
+func TestFlagEnv(t *testing.T) {
+	ctx := context.Background()
+
+	serve := newTestCmd("serve")
+	ip := serve.flags.String("ip", "0.0.0.0", "address to listen on")
+	SetFlagInfo(serve.flags, "ip", FlagInfo{Env: "SUBCMD_TEST_IP"})
+
+	var sb strings.Builder
+	writeFlag(&sb, serve.flags.Lookup("ip"), new(FlagListing), helpStyle{})
+	if want := `(default "0.0.0.0", env SUBCMD_TEST_IP)`; !strings.Contains(sb.String(), want) {
+		t.Fatalf("want %q in the flag help, got %q", want, sb.String())
+	}
+
+	t.Setenv("SUBCMD_TEST_IP", "127.0.0.1")
+	if err := Run(ctx, []Command{serve}, []string{"serve"}); err != nil {
+		t.Fatal(err)
+	}
+	if *ip != "127.0.0.1" {
+		t.Fatalf("want flag value from the environment, got %q", *ip)
+	}
+	if err := Run(ctx, []Command{serve}, []string{"serve", "-ip", "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if *ip != "10.0.0.1" {
+		t.Fatalf("want command-line to override the environment, got %q", *ip)
+	}
+}
+
+func TestBuiltins(t *testing.T) {
+	ctx := context.Background()
+
+	help := newTestCmd("help")
+	cmds := []Command{help, newTestCmd("run")}
+	if err := Run(ctx, cmds, []string{"help", "run"}, WithBuiltins("commands")); err != nil {
+		t.Fatal(err)
+	}
+	if len(help.args) != 1 || help.args[0] != "run" {
+		t.Fatalf("want the custom help command to run, got args %q", help.args)
+	}
+	if err := Run(ctx, cmds, []string{"flags"}, WithBuiltins("commands")); err == nil {
+		t.Fatalf("want the disabled flags command to fail")
+	}
+
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: cmds}
+	WithBuiltins("commands")(&cg.opts)
+	var names []string
+	for _, sub := range getSubcommands([]*cmdData{{fset: flag.CommandLine, cmd: cg}}) {
+		names = append(names, sub[0])
+	}
+	if want := "commands,,help,run"; strings.Join(names, ",") != want {
+		t.Fatalf("want subcommands %q, got %q", want, strings.Join(names, ","))
+	}
+}
+
+type secretValue string
+
+func (v *secretValue) String() string     { return string(*v) }
+func (v *secretValue) Set(s string) error { *v = secretValue(s); return nil }
+func (v *secretValue) IsSecret() bool     { return true }
+
+func TestSnapshotFlags(t *testing.T) {
+	fset := flag.NewFlagSet("login", flag.ContinueOnError)
+	fset.String("user", "", "user name")
+	fset.Var(new(secretValue), "token", "access token")
+	if err := fset.Parse([]string{"-user", "alice", "-token", "abc123"}); err != nil {
+		t.Fatal(err)
+	}
+	values := SnapshotFlags(fset)
+	if values["user"] != "alice" || values["token"] != redacted {
+		t.Fatalf("want user value and redacted token, got %v", values)
+	}
+}
+
+type describedCmd struct {
+	TestCmd
+}
+
+func (c *describedCmd) CommandSynopsis() string { return "Deploys the service." }
+
+func (c *describedCmd) CommandDescription() string {
+	return "Builds the release artifacts first. Then rolls them out.\n"
+}
+
+func TestSynopsisAndDescription(t *testing.T) {
+	c := &describedCmd{TestCmd: *newTestCmd("deploy")}
+	if got := getSynopsis(c); got != "Deploys the service." {
+		t.Fatalf("want explicit synopsis, got %q", got)
+	}
+	if got := getHelpDoc(c); !strings.HasPrefix(got, "Builds the release") {
+		t.Fatalf("want explicit description, got %q", got)
+	}
+	if got := getSynopsis(newTestCmd("run")); got != "First line of help output is used as synopsis." {
+		t.Fatalf("want synopsis from the help text, got %q", got)
+	}
+	if got := Synopsis(Group("db", "Manages the databases. Tables are created on demand.")); got != "Manages the databases." {
+		t.Fatalf("want first sentence of the group description, got %q", got)
+	}
+}
+
+type countingCmd struct {
+	calls int
+}
+
+func (c *countingCmd) Command() (*flag.FlagSet, MainFunc) {
+	c.calls++
+	return flag.NewFlagSet("count", flag.ContinueOnError), func(context.Context, []string) error { return nil }
+}
+
+func TestNodeOf(t *testing.T) {
+	ctx := context.Background()
+
+	c := new(countingCmd)
+	cmds := []Command{c, newTestCmd("run")}
+	for _, args := range [][]string{{"count"}, {"help"}, {"commands"}, {"help", "-all"}} {
+		c.calls = 0
+		if err := Run(ctx, cmds, args, WithOutput(io.Discard, io.Discard)); err != nil {
+			t.Fatal(err)
+		}
+		if c.calls != 1 {
+			t.Fatalf("%q: want Command method called once, got %d calls", args, c.calls)
+		}
+	}
+
+	nodes := new(NodeCache)
+	if nodes.NodeOf(c) != nodes.NodeOf(Hidden(c)) || c.calls != 2 {
+		t.Fatalf("want memoized node shared with the hidden command")
+	}
+
+	// commands with non-pointer types are not memoized
+	type valueCmd struct{ *countingCmd }
+	v := valueCmd{new(countingCmd)}
+	nodes.NodeOf(v)
+	nodes.NodeOf(v)
+	if v.calls != 2 {
+		t.Fatalf("want Command method called for every use, got %d calls", v.calls)
+	}
+}
+
+func TestHelpTopics(t *testing.T) {
+	ctx := context.Background()
+
+	RegisterHelpTopic("environment", "Environment variables used by the tool.\n\nTOOL_HOME sets the data directory.\n")
+	cmds := []Command{newTestCmd("run")}
+
+	var buf bytes.Buffer
+	if err := Run(ctx, cmds, []string{"help", "environment"}, WithOutput(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "TOOL_HOME sets the data directory.") {
+		t.Fatalf("want help topic text, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := Run(ctx, cmds, []string{"help"}, WithOutput(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Help Topics:") || !strings.Contains(buf.String(), "Environment variables used by the tool.") {
+		t.Fatalf("want help topics listed in the help, got %q", buf.String())
+	}
+}
+
+func TestWhich(t *testing.T) {
+	ctx := context.Background()
+
+	create := newTestCmd("create")
+	full := create.flags.Bool("full", false, "take a full backup")
+	cmds := []Command{
+		Group("db", "manage database", MovedCommand("db backup", "backup create")),
+		Group("backup", "manage backups", create),
+	}
+
+	var buf bytes.Buffer
+	if err := Run(ctx, cmds, []string{"which", "db", "backup", "-full", "now"}, WithOutput(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if create.args != nil {
+		t.Fatalf("want command not to run, got args %v", create.args)
+	}
+	if *full {
+		t.Fatalf("want -full flag unchanged after which, got true")
+	}
+	for _, want := range []string{"Moved: ", " db backup -> ", " backup create\n", "Source: core\n", `Args: "now"`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Fatalf("want %q in the output, got %q", want, buf.String())
+		}
+	}
+}
+
+func TestVersion(t *testing.T) {
+	ctx := context.Background()
+
+	cmds := []Command{Version(func() string { return "v1.2.3" }), newTestCmd("run")}
+	for _, args := range [][]string{{"version"}, {"-version"}} {
+		var buf bytes.Buffer
+		if err := Run(ctx, cmds, args, WithOutput(&buf, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(buf.String(), "Version: v1.2.3\n") {
+			t.Fatalf("want the custom version for %q, got %q", args, buf.String())
+		}
+	}
+	if err := Run(ctx, []Command{newTestCmd("run")}, []string{"-version"}); err == nil {
+		t.Fatalf("want -version flag to fail without the version command")
+	}
+}
+
+func TestValidateArgs(t *testing.T) {
+	ctx := context.Background()
+
+	pause, resume := newTestCmd("pause"), newTestCmd("resume")
+	job := ValidateArgs(Group("job", "manage single job", pause, resume), ArgMatches(0, regexp.MustCompile(`^J-\d+$`)))
+	cmds := []Command{job}
+
+	if err := Run(ctx, cmds, []string{"job", "pause", "J-42"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(ctx, cmds, []string{"job", "resume", "42"}); err == nil || resume.args != nil {
+		t.Fatalf("want error for an invalid job id, got %v", err)
+	}
+	if err := Run(ctx, cmds, []string{"job", "resume"}); err == nil {
+		t.Fatalf("want error for a missing job id")
+	}
+}
+
+type deprecatedCmd struct {
+	TestCmd
+}
+
+func (c *deprecatedCmd) CommandDeprecated() string { return "use backup create instead" }
+
+func TestDeprecated(t *testing.T) {
+	ctx := context.Background()
+
+	backup := &deprecatedCmd{TestCmd: *newTestCmd("backup")}
+	backup.flags.Bool("all", false, "back up everything")
+	SetFlagInfo(backup.flags, "all", FlagInfo{Deprecated: "it is the default now"})
+	cmds := []Command{backup, newTestCmd("run")}
+
+	var stdout, stderr bytes.Buffer
+	if err := Run(ctx, cmds, []string{"backup", "-all"}, WithOutput(&stdout, &stderr)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`command "backup" is deprecated: use backup create instead`, "flag -all is deprecated: it is the default now"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Fatalf("want warning %q, got %q", want, stderr.String())
+		}
+	}
+
+	if err := Run(ctx, cmds, []string{"help"}, WithOutput(&stdout, &stderr)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(ctx, cmds, []string{"help", "backup"}, WithOutput(&stdout, &stderr)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[DEPRECATED] First line", "DEPRECATED: use backup create instead", "(DEPRECATED: it is the default now)"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Fatalf("want %q in the help, got %q", want, stdout.String())
+		}
+	}
+}
+
+func TestHelpAlignment(t *testing.T) {
+	ctx := context.Background()
+
+	db := Group("db", "Database commands.", newTestCmd("scan"), newTestCmd("rebuild-all-indexes"))
+	cg := &cmdGroup{flags: flag.CommandLine, subcmds: []Command{db}}
+	cmdseq, _, err := cg.resolve(ctx, []string{"-color=never", "db"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := cg.printHelp(ctx, &sb, cmdseq); err != nil {
+		t.Fatal(err)
+	}
+	var columns []int
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if i := strings.Index(line, "First line of help"); i >= 0 {
+			columns = append(columns, i)
+		}
+	}
+	if len(columns) != 2 || columns[0] != columns[1] {
+		t.Fatalf("want two aligned synopses, got columns %v in %q", columns, sb.String())
+	}
+}
+
+func TestLandingPage(t *testing.T) {
+	ctx := context.Background()
+
+	page := LandingPage{
+		Intro: "Tool manages the job queues.",
+		Tasks: []Example{{Description: "Run a job", Command: "tool run job.yaml"}},
+	}
+	var buf bytes.Buffer
+	if err := Run(ctx, []Command{newTestCmd("run")}, []string{"help"}, WithLandingPage(page), WithOutput(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	intro, tasks, subcmds := strings.Index(out, "Tool manages"), strings.Index(out, "Common Tasks:"), strings.Index(out, "Subcommands:")
+	if intro < 0 || tasks < intro || subcmds < tasks || !strings.Contains(out, "\t$ tool run job.yaml") {
+		t.Fatalf("want intro and common tasks before the subcommands, got %q", out)
+	}
+}
+
+func TestLongDefaults(t *testing.T) {
+	ctx := context.Background()
+
+	flag.CommandLine.String("test-long-default", strings.Repeat("/very/long/path", 5), "data directory")
+	cmds := []Command{newTestCmd("run")}
+
+	var buf bytes.Buffer
+	if err := Run(ctx, cmds, []string{"flags"}, WithOutput(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if want := `(default "/very/long/path/very/long/path/very/l...")`; !strings.Contains(buf.String(), want) {
+		t.Fatalf("want shortened default %q, got %q", want, buf.String())
+	}
+
+	buf.Reset()
+	if err := Run(ctx, cmds, []string{"flags", "-verbose"}, WithOutput(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if want := strconv.Quote(strings.Repeat("/very/long/path", 5)); !strings.Contains(buf.String(), want) {
+		t.Fatalf("want full default %s, got %q", want, buf.String())
+	}
+}
+
+func TestAdvancedFlags(t *testing.T) {
+	ctx := context.Background()
+
+	scan := newTestCmd("scan")
+	scan.flags.Int("limit", 0, "max number of items")
+	scan.flags.Int("shard-buffer", 0, "buffer size per shard")
+	SetFlagInfo(scan.flags, "shard-buffer", FlagInfo{Advanced: true})
+	cmds := []Command{scan}
+
+	var buf bytes.Buffer
+	if err := Run(ctx, cmds, []string{"help", "scan"}, WithOutput(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "-shard-buffer") || !strings.Contains(out, "1 advanced flag is not shown") {
+		t.Fatalf("want advanced flag hidden with a hint, got %q", out)
+	}
+
+	buf.Reset()
+	if err := Run(ctx, cmds, []string{"-help-all", "scan"}, WithOutput(&buf, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "-shard-buffer") {
+		t.Fatalf("want advanced flag with -help-all, got %q", buf.String())
+	}
+}
+
+type listValue []string
+
+func (v *listValue) String() string { return strings.Join(*v, ",") }
+
+func (v *listValue) Set(s string) error {
+	*v = append(*v, s)
+	return nil
+}
+
+type tagCmd struct {
+	tags listValue
+	seen []int
+}
+
+func (c *tagCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet("tag", flag.ContinueOnError)
+	fset.Var(&c.tags, "t", "adds a tag")
+	return fset, func(ctx context.Context, args []string) error {
+		c.seen = append(c.seen, len(c.tags))
+		fmt.Fprintln(cmdctx.Stdout(ctx), "tagged")
+		return nil
+	}
+}
+
+func TestBenchFlags(t *testing.T) {
+	ctx := context.Background()
+
+	var stdout bytes.Buffer
+	tag := new(tagCmd)
+	if err := Run(ctx, []Command{tag, Bench()}, []string{"bench", "-count", "3", "tag", "-t", "x"}, WithOutput(&stdout, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if len(tag.seen) != 3 || tag.seen[0] != 1 || tag.seen[2] != 1 {
+		t.Fatalf("want one tag in every run, got %v", tag.seen)
+	}
+	if strings.Contains(stdout.String(), "tagged") {
+		t.Fatalf("want the command output discarded, got %q", stdout.String())
+	}
+}
+
+func TestDebugResolve(t *testing.T) {
+	ctx := context.Background()
+
+	cmds := []Command{newTestCmd("run")}
+	for _, test := range []struct {
+		flag  string
+		trace bool
+	}{
+		{"-debug-resolve", true},
+		{"--debug-resolve=true", true},
+		{"-debug-resolve=false", false},
+	} {
+		var stderr bytes.Buffer
+		if err := Run(ctx, cmds, []string{test.flag, "run"}, WithOutput(io.Discard, &stderr)); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(stderr.String(), "resolve: "); got != test.trace {
+			t.Fatalf("%s: want trace %t, got %q", test.flag, test.trace, stderr.String())
+		}
+	}
+	if err := Run(ctx, cmds, []string{"-debug-resolve=maybe", "run"}); err == nil {
+		t.Fatalf("want error for an invalid -debug-resolve value")
+	}
+	// flags after the command arguments are arguments
+	run := cmds[0].(*TestCmd)
+	if err := Run(ctx, cmds, []string{"run", "a", "-debug-resolve"}, WithOutput(io.Discard, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	if len(run.args) != 2 || run.args[1] != "-debug-resolve" {
+		t.Fatalf("want -debug-resolve passed as an argument, got %q", run.args)
+	}
+
+	// commands can define their own -debug-resolve flag
+	own := newTestCmd("own")
+	debug := own.flags.Bool("debug-resolve", false, "debugs the resolver of the command")
+	var stderr bytes.Buffer
+	if err := Run(ctx, []Command{own}, []string{"own", "-debug-resolve"}, WithOutput(io.Discard, &stderr)); err != nil {
+		t.Fatal(err)
+	}
+	if !*debug || strings.Contains(stderr.String(), "resolve: ") {
+		t.Fatalf("want the command flag set without a trace, got %t and %q", *debug, stderr.String())
+	}
+}
+
+func TestDetachRunning(t *testing.T) {
+	ctx := context.Background()
+
+	pidFile := filepath.Join(t.TempDir(), "daemon.pid")
+	if err := writePidFile(ctx, pidFile, os.Getpid(), []string{"serve"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var status string
+	serve := New("serve", "Runs the daemon.", func(ctx context.Context, args []string) error {
+		status = statusCommand(ctx, pidFile, os.Getpid())
+		return Detach(ctx, "background", pidFile)
+	})
+	cmds := []Command{serve, Group("daemon", "Manages the daemon.", StatusCommand(pidFile))}
+	if err := Run(ctx, cmds, []string{"serve"}); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("want ErrExist for a running process, got %v", err)
+	}
+	if !strings.HasSuffix(status, " daemon status") {
+		t.Fatalf("want the status command from the tree, got %q", status)
+	}
+}
+
+func TestStopStalePidFile(t *testing.T) {
+	ctx := context.Background()
+
+	// pid of an exited child process is not running
+	child := exec.Command(os.Args[0], "-test.run=^$")
+	if err := child.Run(); err != nil {
+		t.Fatal(err)
+	}
+	pid := child.Process.Pid
+
+	pidFile := filepath.Join(t.TempDir(), "daemon.pid")
+	if err := writePidFile(ctx, pidFile, pid, []string{"serve"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	if err := Run(ctx, []Command{StopCommand(pidFile)}, []string{"stop"}, WithOutput(&stdout, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	if want := msg(MsgStalePidFile, pid, pidFile); !strings.Contains(stdout.String(), want) {
+		t.Fatalf("want %q in the output, got %q", want, stdout.String())
+	}
+	if _, err := os.Stat(pidFile); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want the stale pid file removed, got %v", err)
+	}
+}
+
+type limitedCmd struct {
+	TestCmd
+	procs int
+}
+
+func (c *limitedCmd) Command() (*flag.FlagSet, MainFunc) {
+	return c.flags, func(context.Context, []string) error {
+		c.procs = runtime.GOMAXPROCS(0)
+		return nil
+	}
+}
+
+func (c *limitedCmd) CommandLimits() Limits {
+	return Limits{CPUs: 1}
+}
+
+func TestLimitsCPUs(t *testing.T) {
+	ctx := context.Background()
+
+	old := runtime.GOMAXPROCS(0)
+	c := &limitedCmd{TestCmd: *newTestCmd("batch")}
+	if err := Run(ctx, []Command{c}, []string{"batch"}); err != nil {
+		t.Fatal(err)
+	}
+	if c.procs != 1 || runtime.GOMAXPROCS(0) != old {
+		t.Fatalf("want GOMAXPROCS 1 while running and %d after, got %d and %d", old, c.procs, runtime.GOMAXPROCS(0))
+	}
+}
+
+type instanceOps struct{}
+
+func (instanceOps) Get(context.Context, []string) error    { return nil }
+func (instanceOps) List(context.Context, []string) error   { return nil }
+func (instanceOps) Create(context.Context, []string) error { return nil }
+func (instanceOps) Update(context.Context, []string) error { return nil }
+func (instanceOps) Delete(context.Context, []string) error { return nil }
+
+func TestResourceSynopsis(t *testing.T) {
+	want := map[string]string{
+		"instance":        "Manage instance resources.",
+		"instance get":    "Prints instance details.",
+		"instance list":   "Lists instance resources.",
+		"instance create": "Creates instance resources.",
+		"instance update": "Updates instance resources.",
+		"instance delete": "Deletes instance resources.",
+	}
+	spec := NewSpec("tool", []Command{Resource("instance", instanceOps{})})
+	if len(spec.Commands) != len(want) {
+		t.Fatalf("want %d commands, got %d", len(want), len(spec.Commands))
+	}
+	for _, c := range spec.Commands {
+		if c.Synopsis != want[c.Path] {
+			t.Fatalf("want synopsis %q for %q, got %q", want[c.Path], c.Path, c.Synopsis)
+		}
+	}
+}
+
+func TestDoctorSummary(t *testing.T) {
+	ctx := context.Background()
+
+	pass := func(ctx context.Context) error { return nil }
+	warn := func(ctx context.Context) error { return errors.New("disk is almost full") }
+	checks := []HealthCheck{
+		{Name: "config", Check: pass},
+		{Name: "disk", Severity: SeverityWarn, Check: warn},
+	}
+
+	var stdout bytes.Buffer
+	if err := Run(ctx, []Command{Doctor(checks...)}, []string{"doctor"}, WithOutput(&stdout, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "1 passed, 1 warning, 0 failed") {
+		t.Fatalf("want singular warning count in the summary, got %q", stdout.String())
+	}
+
+	stdout.Reset()
+	checks = append(checks, HealthCheck{Name: "memory", Severity: SeverityWarn, Check: warn})
+	if err := Run(ctx, []Command{Doctor(checks...)}, []string{"doctor"}, WithOutput(&stdout, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "1 passed, 2 warnings, 0 failed") {
+		t.Fatalf("want plural warning count in the summary, got %q", stdout.String())
+	}
+}
+
+type upperCmd struct{}
+
+func (upperCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet("upper", flag.ContinueOnError)
+	AddRedirectFlags(fset)
+	return fset, func(ctx context.Context, args []string) error {
+		data, err := io.ReadAll(cmdctx.Stdin(ctx))
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(cmdctx.Stdout(ctx), strings.ToUpper(string(data))); err != nil {
+			return err
+		}
+		if len(args) > 0 {
+			return errors.New(args[0])
+		}
+		return nil
+	}
+}
+
+func TestRedirectFlags(t *testing.T) {
+	ctx := context.Background()
+
+	cmds := []Command{upperCmd{}}
+
+	dir := t.TempDir()
+	input, output := filepath.Join(dir, "input.txt"), filepath.Join(dir, "output.txt")
+	if err := os.WriteFile(input, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	args := []string{"upper", "-input", input, "-output-file", output}
+	if err := Run(ctx, cmds, args, WithOutput(&stdout, io.Discard)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(output); err != nil || string(data) != "HELLO" {
+		t.Fatalf("want HELLO in the output file, got %q (%v)", data, err)
+	}
+	if stdout.Len() != 0 {
+		t.Fatalf("want no output on the stdout, got %q", stdout.String())
+	}
+
+	// failed runs leave the output file unchanged
+	if err := os.WriteFile(input, []byte("world"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Run(ctx, cmds, append(args, "failed"), WithOutput(&stdout, io.Discard)); err == nil {
+		t.Fatalf("want error from the command, got nil")
+	}
+	if data, err := os.ReadFile(output); err != nil || string(data) != "HELLO" {
+		t.Fatalf("want old contents in the output file, got %q (%v)", data, err)
+	}
+
+	if err := Run(ctx, cmds, []string{"upper", "-input", filepath.Join(dir, "missing.txt")}); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("want ErrNotExist for a missing input file, got %v", err)
+	}
+}
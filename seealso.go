@@ -0,0 +1,27 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import "strings"
+
+// getSeeAlso returns the space separated paths, relative to the program name,
+// of the commands related to a command, which are collected through the
+// optional `interface{ CommandSeeAlso() []string }` method.
+func getSeeAlso(c Command) []string {
+	v, ok := c.(interface{ CommandSeeAlso() []string })
+	if !ok {
+		return nil
+	}
+	var paths []string
+	for _, p := range v.CommandSeeAlso() {
+		if p = strings.Join(strings.Fields(p), " "); len(p) > 0 {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// anchorName returns the Markdown anchor name for a command path.
+func anchorName(path string) string {
+	return strings.Join(strings.Fields(path), "-")
+}
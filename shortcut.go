@@ -0,0 +1,172 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type shortcutCmd struct {
+	op   string
+	file string
+}
+
+// Shortcuts creates a "shortcut" command group with "save", "list" and
+// "delete" subcommands to manage saved command invocations, like git aliases.
+// Shortcuts are stored as JSON in the `file`. When the returned command is a
+// top-level command of the tree, a saved shortcut name can be used in place
+// of a top-level command, in which case, it is replaced by the saved
+// arguments followed by the remaining command-line arguments.
+//
+//	$ mytool shortcut save recent -- db scan -limit 10
+//	$ mytool recent jobs/
+//
+// Saved arguments are validated against the current command tree when they
+// are saved.
+func Shortcuts(file string) Command {
+	return &cmdGroup{
+		flags:    flag.NewFlagSet("shortcut", flag.ContinueOnError),
+		synopsis: "Manage saved command shortcuts.",
+		subcmds: []Command{
+			&shortcutCmd{op: "save", file: file},
+			&shortcutCmd{op: "list", file: file},
+			&shortcutCmd{op: "delete", file: file},
+		},
+		shortcuts: file,
+	}
+}
+
+func (c *shortcutCmd) Command() (*flag.FlagSet, MainFunc) {
+	return flag.NewFlagSet(c.op, flag.ContinueOnError), c.run
+}
+
+func (c *shortcutCmd) CommandHelp() string {
+	switch c.op {
+	case "save":
+		return `Saves a command invocation as a shortcut.
+
+First argument is the shortcut name and the remaining arguments are the
+command names, flags and arguments of the invocation. Use "--" before the
+invocation so that it's flags are not interpreted by the save command.
+`
+	case "list":
+		return "Lists all saved shortcuts."
+	default:
+		return "Deletes the named shortcuts."
+	}
+}
+
+func (c *shortcutCmd) run(ctx context.Context, args []string) error {
+	shortcuts, err := loadShortcuts(c.file)
+	if err != nil {
+		return err
+	}
+
+	switch c.op {
+	case "list":
+		var names []string
+		for name := range shortcuts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			var quoted []string
+			for _, arg := range shortcuts[name] {
+				quoted = append(quoted, strconv.Quote(arg))
+			}
+			fmt.Fprintf(getStdout(ctx), "\t%-15s  %s\n", name, strings.Join(quoted, " "))
+		}
+		return nil
+
+	case "delete":
+		for _, name := range args {
+			delete(shortcuts, name)
+		}
+		return saveShortcuts(ctx, c.file, shortcuts)
+	}
+
+	if len(args) < 2 || (len(args) == 2 && args[1] == "--") {
+		return fmt.Errorf("%s: %w", msg(MsgShortcutNeedsArgs), os.ErrInvalid)
+	}
+	name, saved := args[0], args[1:]
+	if saved[0] == "--" {
+		saved = saved[1:]
+	}
+	root, ok := rootKey.Value(ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", msg(MsgNeedsRun, "shortcut save"), os.ErrInvalid)
+	}
+	if root.isBuiltin(name) || slices.Contains(hiddenSpecialCmds, name) {
+		return fmt.Errorf("%s: %w", msg(MsgShortcutIsCommand, name), os.ErrExist)
+	}
+	for _, sub := range root.subcmds {
+		if root.nodes.name(sub) == name {
+			return fmt.Errorf("%s: %w", msg(MsgShortcutIsCommand, name), os.ErrExist)
+		}
+	}
+	// saved arguments are checked without changing the flags of this process
+	restore := root.saveFlagValues()
+	_, _, err = root.rerun().resolve(ctx, saved)
+	restore()
+	if err != nil {
+		return fmt.Errorf("%s: %w", msg(MsgShortcutInvalid, name), err)
+	}
+	shortcuts[name] = saved
+	return saveShortcuts(ctx, c.file, shortcuts)
+}
+
+func loadShortcuts(file string) (map[string][]string, error) {
+	shortcuts := make(map[string][]string)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return shortcuts, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &shortcuts); err != nil {
+		return nil, fmt.Errorf("%s: %w", msg(MsgShortcutsFileInvalid, file), err)
+	}
+	return shortcuts, nil
+}
+
+func saveShortcuts(ctx context.Context, file string, shortcuts map[string][]string) error {
+	data, err := json.MarshalIndent(shortcuts, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	return WriteFileAtomic(ctx, file, 0o644, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// lookupShortcut returns the saved arguments for a shortcut name when the
+// command group has a shortcuts command as a direct subcommand.
+func (cg *cmdGroup) lookupShortcut(name string) ([]string, bool) {
+	for _, c := range cg.subcmds {
+		if sg, ok := c.(*cmdGroup); ok && len(sg.shortcuts) > 0 {
+			shortcuts, err := loadShortcuts(sg.shortcuts)
+			if err != nil {
+				return nil, false
+			}
+			saved, ok := shortcuts[name]
+			return saved, ok
+		}
+	}
+	return nil, false
+}
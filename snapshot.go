@@ -0,0 +1,62 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"flag"
+	"reflect"
+)
+
+// redacted replaces the secret flag values in the outputs.
+const redacted = "<redacted>"
+
+// SnapshotFlags returns the effective values of all flags in the flag set,
+// keyed by the flag names, which are typically captured after the
+// command-line is parsed. Values of the flags implementing the
+// `interface{ IsSecret() bool }` method are redacted. Returned map is a copy,
+// so it can be shared with other goroutines, like for logging, while the flag
+// values are changed.
+func SnapshotFlags(fset *flag.FlagSet) map[string]string {
+	values := make(map[string]string)
+	fset.VisitAll(func(f *flag.Flag) {
+		if isSecret(f) {
+			values[f.Name] = redacted
+			return
+		}
+		values[f.Name] = f.Value.String()
+	})
+	return values
+}
+
+// saveFlagValues saves the values of all flags in the command tree of the root
+// command group, including the global flags, and returns a function that
+// restores them, so that resolving a command-line only to check it leaves the
+// flags unchanged. Values are copied shallowly, so changes to the data shared
+// through pointers or maps are not restored.
+func (cg *cmdGroup) saveFlagValues() (restore func()) {
+	type savedValue struct {
+		dst, src reflect.Value
+	}
+	var saved []savedValue
+	save := func(fset *flag.FlagSet) {
+		fset.VisitAll(func(f *flag.Flag) {
+			v := reflect.ValueOf(f.Value)
+			if v.Kind() != reflect.Pointer || v.IsNil() {
+				return
+			}
+			src := reflect.New(v.Elem().Type()).Elem()
+			src.Set(v.Elem())
+			saved = append(saved, savedValue{dst: v.Elem(), src: src})
+		})
+	}
+	save(flag.CommandLine)
+	walkTree(cg.nodes, nil, cg.subcmds, func(_ []string, c Command) bool {
+		save(cg.nodes.NodeOf(c).FlagSet)
+		return true
+	})
+	return func() {
+		for _, v := range saved {
+			v.dst.Set(v.src)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Spec is a machine readable description of a command tree, which can be
+// exported as JSON from one binary and compared with the spec from another.
+type Spec struct {
+	// Name is the program name.
+	Name string `json:"name"`
+
+	// Commands lists all commands in the tree in the depth-first order.
+	Commands []*CommandSpec `json:"commands"`
+}
+
+// CommandSpec describes a single command in the spec.
+type CommandSpec struct {
+	// Name is the command name, which is the last word in the path.
+	Name string `json:"name"`
+
+	// Path is the space separated command path without the program name.
+	Path string `json:"path"`
+
+	// Group is true for command groups.
+	Group bool `json:"group,omitempty"`
+
+	// Synopsis is the one line description of the command.
+	Synopsis string `json:"synopsis,omitempty"`
+
+	// Usage holds the placeholders for the positional arguments, like
+	// "<key> <value>", which is empty for the command groups.
+	Usage string `json:"usage,omitempty"`
+
+	// Plugin is the name of the plugin that provides the command, which is
+	// empty for the core commands.
+	Plugin string `json:"plugin,omitempty"`
+
+	// Deprecated is the deprecation message for the deprecated commands.
+	Deprecated string `json:"deprecated,omitempty"`
+
+	// Annotations holds the key/value annotations of the command.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Flags lists the flags defined by the command itself.
+	Flags []*FlagSpec `json:"flags,omitempty"`
+}
+
+// FlagSpec describes a single flag in the spec.
+type FlagSpec struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Default    string `json:"default,omitempty"`
+	Usage      string `json:"usage,omitempty"`
+	Env        string `json:"env,omitempty"`
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+// NewSpec returns the spec for the command tree with the names, paths,
+// synopses, argument placeholders and the flags with their defaults for all
+// visible commands, so that external tools, like documentation sites and
+// completion generators, can use the structured metadata. Program name is
+// taken from the `name` parameter. Spec is also printed as JSON by the hidden
+// builtin "__spec" command.
+func NewSpec(name string, cmds []Command) *Spec {
+	return newSpec(new(NodeCache), name, cmds)
+}
+
+func newSpec(nodes *NodeCache, name string, cmds []Command) *Spec {
+	spec := &Spec{Name: name}
+	walkCommands(nodes, nil, cmds, func(path []string, c Command) {
+		fs := nodes.NodeOf(c).FlagSet
+		cspec := &CommandSpec{
+			Name:        path[len(path)-1],
+			Path:        strings.Join(path, " "),
+			Synopsis:    getSynopsis(c),
+			Plugin:      getOrigin(c),
+			Deprecated:  getDeprecated(c),
+			Annotations: getAnnotations(c),
+		}
+		if _, ok := c.(*cmdGroup); ok {
+			cspec.Group = true
+		} else {
+			cspec.Usage = getArgsUsage(c)
+		}
+		for _, f := range listFlags(fs) {
+			_, usage := flag.UnquoteUsage(f)
+			cspec.Flags = append(cspec.Flags, &FlagSpec{
+				Name:       f.Name,
+				Type:       flagType(f),
+				Default:    f.DefValue,
+				Usage:      usage,
+				Env:        getFlagInfo(f).Env,
+				Deprecated: getFlagInfo(f).Deprecated,
+			})
+		}
+		spec.Commands = append(spec.Commands, cspec)
+	})
+	return spec
+}
+
+// flagType returns a short name for the flag value type, like "int" for the
+// flags defined with flag.Int function.
+func flagType(f *flag.Flag) string {
+	typ := reflect.TypeOf(f.Value)
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.PkgPath() != "flag" {
+		return typ.String()
+	}
+	return strings.TrimSuffix(typ.Name(), "Value")
+}
+
+// WriteSpec writes the spec for the command tree as JSON.
+func WriteSpec(w io.Writer, name string, cmds []Command) error {
+	return writeSpec(w, NewSpec(name, cmds))
+}
+
+func writeSpec(w io.Writer, spec *Spec) error {
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// ReadSpec reads a spec written by the WriteSpec function.
+func ReadSpec(r io.Reader) (*Spec, error) {
+	spec := new(Spec)
+	if err := json.NewDecoder(r).Decode(spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// readSpecFile reads the spec from a file.
+func readSpecFile(path string) (*Spec, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	spec, err := ReadSpec(fp)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", msg(MsgSpecFileInvalid, path), err)
+	}
+	return spec, nil
+}
+
+// specChangeKind identifies the type of a difference between two specs.
+type specChangeKind int
+
+const (
+	commandAdded specChangeKind = iota
+	commandRemoved
+	flagAdded
+	flagRemoved
+	flagTypeChanged
+	flagDefaultChanged
+)
+
+// specChange describes a single difference between two specs.
+type specChange struct {
+	kind     specChangeKind
+	path     string
+	flag     string
+	from, to string
+}
+
+// breaking returns true if the change could break existing invocations.
+func (c *specChange) breaking() bool {
+	return c.kind != commandAdded && c.kind != flagAdded
+}
+
+func (c *specChange) String() string {
+	switch c.kind {
+	case commandAdded:
+		return msg(MsgSpecCommandAdded, c.path)
+	case commandRemoved:
+		return msg(MsgSpecCommandRemoved, c.path)
+	case flagAdded:
+		return msg(MsgSpecFlagAdded, c.path, c.flag)
+	case flagRemoved:
+		return msg(MsgSpecFlagRemoved, c.path, c.flag)
+	case flagTypeChanged:
+		return msg(MsgSpecFlagTypeChanged, c.path, c.flag, c.from, c.to)
+	default:
+		return msg(MsgSpecFlagDefaultChanged, c.path, c.flag, c.from, c.to)
+	}
+}
+
+// diffSpecs returns the differences from the old spec to the new spec in the
+// order of the commands in the specs.
+func diffSpecs(from, to *Spec) []*specChange {
+	oldCmds := make(map[string]*CommandSpec)
+	for _, c := range from.Commands {
+		oldCmds[c.Path] = c
+	}
+	newCmds := make(map[string]*CommandSpec)
+	for _, c := range to.Commands {
+		newCmds[c.Path] = c
+	}
+
+	var changes []*specChange
+	for _, oc := range from.Commands {
+		if _, ok := newCmds[oc.Path]; !ok {
+			changes = append(changes, &specChange{kind: commandRemoved, path: oc.Path})
+		}
+	}
+	for _, nc := range to.Commands {
+		oc, ok := oldCmds[nc.Path]
+		if !ok {
+			changes = append(changes, &specChange{kind: commandAdded, path: nc.Path})
+			continue
+		}
+
+		oldFlags := make(map[string]*FlagSpec)
+		for _, f := range oc.Flags {
+			oldFlags[f.Name] = f
+		}
+		newFlags := make(map[string]*FlagSpec)
+		for _, f := range nc.Flags {
+			newFlags[f.Name] = f
+		}
+		for _, of := range oc.Flags {
+			if _, ok := newFlags[of.Name]; !ok {
+				changes = append(changes, &specChange{kind: flagRemoved, path: nc.Path, flag: of.Name})
+			}
+		}
+		for _, nf := range nc.Flags {
+			of, ok := oldFlags[nf.Name]
+			switch {
+			case !ok:
+				changes = append(changes, &specChange{kind: flagAdded, path: nc.Path, flag: nf.Name})
+			case of.Type != nf.Type:
+				changes = append(changes, &specChange{kind: flagTypeChanged, path: nc.Path, flag: nf.Name, from: of.Type, to: nf.Type})
+			case of.Default != nf.Default:
+				changes = append(changes, &specChange{kind: flagDefaultChanged, path: nc.Path, flag: nf.Name, from: of.Default, to: nf.Default})
+			}
+		}
+	}
+	return changes
+}
+
+// WriteSpecDiff writes a Markdown formatted changelog of the command-line
+// interface changes from the old spec to the new spec, suitable for the release
+// notes. Nothing is written when the specs are equivalent.
+func WriteSpecDiff(w io.Writer, from, to *Spec) error {
+	changes := diffSpecs(from, to)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Command-line changes\n\n")
+	for _, c := range changes {
+		fmt.Fprintf(&sb, "- %s\n", c)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type specCmd struct{}
+
+func (c *specCmd) Command() (*flag.FlagSet, MainFunc) {
+	return flag.NewFlagSet("spec", flag.ContinueOnError), c.run
+}
+
+func (c *specCmd) CommandHelp() string {
+	return `Prints the command tree spec as JSON.
+
+Specs exported from two different versions of the program can be compared
+with the "docs diff" command.
+`
+}
+
+func (c *specCmd) run(ctx context.Context, args []string) error {
+	root, ok := rootKey.Value(ctx)
+	if !ok {
+		return fmt.Errorf("%s: %w", msg(MsgNeedsRun, "docs spec"), os.ErrInvalid)
+	}
+	return writeSpec(getStdout(ctx), newSpec(root.nodes, getName(root), root.subcmds))
+}
+
+type diffCmd struct{}
+
+func (c *diffCmd) Command() (*flag.FlagSet, MainFunc) {
+	return flag.NewFlagSet("diff", flag.ContinueOnError), c.run
+}
+
+func (c *diffCmd) CommandHelp() string {
+	return `Prints the changes between two command tree specs.
+
+Takes the old and new spec files, exported with the "docs spec" command, as
+arguments and prints the added and removed commands and the changed flags as
+a Markdown formatted changelog.
+`
+}
+
+func (c *diffCmd) run(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("%s: %w", msg(MsgDiffNeedsSpecs), os.ErrInvalid)
+	}
+	from, err := readSpecFile(args[0])
+	if err != nil {
+		return err
+	}
+	to, err := readSpecFile(args[1])
+	if err != nil {
+		return err
+	}
+	return WriteSpecDiff(getStdout(ctx), from, to)
+}
+
+// CheckCompatibility returns a *CompatError if the command tree removes any
+// commands or flags, or changes the flag types or defaults, relative to the
+// baseline spec. It is typically used from a test with a committed baseline
+// spec, so that breaking command-line changes are caught in code review.
+// Added commands and flags are compatible.
+func CheckCompatibility(baseline *Spec, cmds []Command) error {
+	var changes []string
+	for _, c := range diffSpecs(baseline, NewSpec(baseline.Name, cmds)) {
+		if c.breaking() {
+			changes = append(changes, c.String())
+		}
+	}
+	if len(changes) > 0 {
+		return &CompatError{Changes: changes}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Spinner displays an animated spinner with the message on the standard error
+// till the returned stop function is called or the context is canceled. When
+// the standard error is not a terminal, the message is printed just once
+// without any animation. Stop function must be called to release the
+// resources and it is safe to call it multiple times.
+//
+//	stop := subcmd.Spinner(ctx, "contacting server...")
+//	defer stop()
+func Spinner(ctx context.Context, message string) (stop func()) {
+	w := getStderr(ctx)
+	if !isTerminalWriter(w) {
+		fmt.Fprintln(w, message)
+		return func() {}
+	}
+
+	frames := []string{`|`, `/`, `-`, `\`}
+	if TerminalInfo(ctx).Unicode {
+		frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		for i := 0; ; i++ {
+			fmt.Fprintf(w, "\r%s %s", frames[i%len(frames)], message)
+			select {
+			case <-ctx.Done():
+				// clear the spinner line
+				fmt.Fprintf(w, "\r\x1b[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+// Package subcmdtest provides helpers to test the commands implemented with
+// the subcmd package.
+package subcmdtest
+
+import (
+	"context"
+	"flag"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bvkgo/subcmd"
+)
+
+// MaxSynopsisLength is the maximum length for the synopsis of a command that
+// is accepted by the Conformance function, so that the synopses fit the help
+// output on a standard terminal.
+const MaxSynopsisLength = 60
+
+// Conformance checks that a command follows the expectations of the subcmd
+// package, like a non-empty flag set name, the flag.ContinueOnError error
+// handling, a short synopsis and a Command method that returns the same
+// flags every time, and that the help for the command, and all of it's
+// subcommands for the command groups, can be rendered. Problems are reported
+// as test errors.
+func Conformance(t testing.TB, cmd subcmd.Command) {
+	t.Helper()
+
+	fset, _ := cmd.Command()
+	if fset == nil {
+		t.Errorf("Command method returned a nil flag set")
+		return
+	}
+	name := fset.Name()
+	if len(name) == 0 {
+		t.Errorf("flag set name must not be empty")
+		return
+	}
+	if fset.ErrorHandling() != flag.ContinueOnError {
+		t.Errorf("command %q: flag set must use the flag.ContinueOnError error handling", name)
+	}
+
+	again, _ := cmd.Command()
+	if again == nil || again.Name() != name || !slices.Equal(flagNames(again), flagNames(fset)) {
+		t.Errorf("command %q: Command method must return the same flags every time", name)
+	}
+
+	if v, ok := cmd.(interface{ CommandHelp() string }); ok {
+		if len(strings.TrimSpace(v.CommandHelp())) == 0 {
+			t.Errorf("command %q: help text must not be empty", name)
+		}
+	}
+	if synopsis := subcmd.Synopsis(cmd); len(synopsis) > MaxSynopsisLength {
+		t.Errorf("command %q: synopsis %q is longer than %d characters", name, synopsis, MaxSynopsisLength)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("command %q: help rendering panicked: %v", name, r)
+		}
+	}()
+	args := []string{"help", "-all", name}
+	if err := subcmd.Run(context.Background(), []subcmd.Command{cmd}, args, subcmd.WithOutput(io.Discard, io.Discard)); err != nil {
+		t.Errorf("command %q: help rendering failed: %v", name, err)
+	}
+}
+
+func flagNames(fset *flag.FlagSet) []string {
+	var names []string
+	fset.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+	return names
+}
@@ -0,0 +1,15 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmdtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bvkgo/subcmd"
+)
+
+func TestConformance(t *testing.T) {
+	pause := subcmd.New("pause", "Pauses the job.", func(context.Context, []string) error { return nil })
+	Conformance(t, subcmd.Group("job", "Manages a single job.", pause))
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+// RunSummary collects the item counts for the end-of-run summary of batch
+// commands. It is safe for concurrent use.
+type RunSummary struct {
+	start     time.Time
+	enabled   atomic.Bool
+	succeeded atomic.Int64
+	failed    atomic.Int64
+}
+
+// summaryKey holds the end-of-run summary of the invocation.
+var summaryKey = cmdctx.NewKey[*RunSummary]("summary")
+
+func withSummary(ctx context.Context) context.Context {
+	if _, ok := summaryKey.Value(ctx); ok {
+		return ctx
+	}
+	return summaryKey.With(ctx, &RunSummary{start: time.Now()})
+}
+
+// Summary returns the end-of-run summary for the current invocation. Calling
+// this function opts the command into printing a summary line with the
+// succeeded and failed item counts along with the elapsed time after the
+// command's main function returns.
+func Summary(ctx context.Context) *RunSummary {
+	s, ok := summaryKey.Value(ctx)
+	if !ok {
+		s = &RunSummary{start: time.Now()}
+	}
+	s.enabled.Store(true)
+	return s
+}
+
+// Succeeded records successfully processed items.
+func (s *RunSummary) Succeeded(n int) {
+	s.succeeded.Add(int64(n))
+}
+
+// Failed records items that failed processing.
+func (s *RunSummary) Failed(n int) {
+	s.failed.Add(int64(n))
+}
+
+// printSummary prints the summary line if the command has opted into it.
+func printSummary(ctx context.Context, w io.Writer) {
+	s, ok := summaryKey.Value(ctx)
+	if !ok || !s.enabled.Load() {
+		return
+	}
+	elapsed := time.Since(s.start).Round(time.Millisecond)
+	fmt.Fprintln(w, msg(MsgRunSummary, s.succeeded.Load(), s.failed.Load(), elapsed))
+}
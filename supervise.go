@@ -0,0 +1,142 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+type superviseCmd struct {
+	name     string
+	synopsis string
+	pidFile  string
+	timeout  time.Duration
+	hasFlags bool
+	run      func(ctx context.Context, c *superviseCmd) error
+}
+
+func (c *superviseCmd) Command() (*flag.FlagSet, MainFunc) {
+	fset := flag.NewFlagSet(c.name, flag.ContinueOnError)
+	if c.hasFlags {
+		fset.DurationVar(&c.timeout, "timeout", 10*time.Second, "time to wait for the process to exit")
+	}
+	return fset, func(ctx context.Context, args []string) error {
+		return c.run(ctx, c)
+	}
+}
+
+func (c *superviseCmd) CommandHelp() string {
+	return c.synopsis
+}
+
+// StatusCommand creates a "status" command that reports if the background
+// process started by the Detach function with the pid file is running.
+func StatusCommand(pidFile string) Command {
+	return &superviseCmd{
+		name:     "status",
+		synopsis: "Reports if the background process is running.",
+		pidFile:  pidFile,
+		run:      runStatus,
+	}
+}
+
+// StopCommand creates a "stop" command that stops the background process
+// started by the Detach function with the pid file.
+func StopCommand(pidFile string) Command {
+	return &superviseCmd{
+		name:     "stop",
+		synopsis: "Stops the background process.",
+		pidFile:  pidFile,
+		hasFlags: true,
+		run:      runStop,
+	}
+}
+
+// RestartCommand creates a "restart" command that stops the background
+// process started by the Detach function with the pid file and starts it again
+// with the same command-line arguments.
+func RestartCommand(pidFile string) Command {
+	return &superviseCmd{
+		name:     "restart",
+		synopsis: "Restarts the background process.",
+		pidFile:  pidFile,
+		hasFlags: true,
+		run:      runRestart,
+	}
+}
+
+func runStatus(ctx context.Context, c *superviseCmd) error {
+	pid, _, err := readPidFile(c.pidFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(getStdout(ctx), msg(MsgNotRunning))
+			return nil
+		}
+		return err
+	}
+	if !isRunning(pid) {
+		fmt.Fprintln(getStdout(ctx), msg(MsgStalePidFile, pid, c.pidFile))
+		return nil
+	}
+	fmt.Fprintln(getStdout(ctx), msg(MsgRunning, pid))
+	return nil
+}
+
+func runStop(ctx context.Context, c *superviseCmd) error {
+	pid, _, err := readPidFile(c.pidFile)
+	if err != nil {
+		return err
+	}
+	running := isRunning(pid)
+	if err := stopProcess(ctx, pid, c.timeout); err != nil {
+		return err
+	}
+	if err := os.Remove(c.pidFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if !running {
+		fmt.Fprintln(getStdout(ctx), msg(MsgStalePidFile, pid, c.pidFile))
+		return nil
+	}
+	fmt.Fprintln(getStdout(ctx), msg(MsgStopped, pid))
+	return nil
+}
+
+func runRestart(ctx context.Context, c *superviseCmd) error {
+	pid, args, err := readPidFile(c.pidFile)
+	if err != nil {
+		return err
+	}
+	if err := stopProcess(ctx, pid, c.timeout); err != nil {
+		return err
+	}
+	return startDetached(ctx, args, c.pidFile)
+}
+
+// stopProcess requests the process to exit and waits for it to exit till the
+// timeout.
+func stopProcess(ctx context.Context, pid int, timeout time.Duration) error {
+	if !isRunning(pid) {
+		return nil
+	}
+	if err := terminate(pid); err != nil {
+		return fmt.Errorf("%s: %w", msg(MsgStopFailed, pid), err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for isRunning(pid) {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: %w", msg(MsgDidNotExit, pid), context.Cause(ctx))
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return nil
+}
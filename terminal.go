@@ -0,0 +1,99 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+// Terminal describes the output capabilities of the terminal connected to the
+// standard output.
+type Terminal = cmdctx.Terminal
+
+// WithTerminal returns a context with the terminal capabilities overridden by
+// the input, which is useful to fake the capabilities in tests.
+func WithTerminal(ctx context.Context, t *Terminal) context.Context {
+	return cmdctx.WithTerminal(ctx, t)
+}
+
+// TerminalInfo returns the terminal capabilities for the current invocation.
+// Capabilities are probed once per invocation by the Run function and can be
+// overridden with the WithTerminal function.
+func TerminalInfo(ctx context.Context) *Terminal {
+	if t := cmdctx.TerminalInfo(ctx); t != nil {
+		return t
+	}
+	return detectTerminal()
+}
+
+func withTerminal(ctx context.Context) context.Context {
+	if cmdctx.TerminalInfo(ctx) != nil {
+		return ctx
+	}
+	return WithTerminal(ctx, detectTerminal())
+}
+
+func detectTerminal() *Terminal {
+	t := new(Terminal)
+	if fi, err := os.Stdout.Stat(); err == nil {
+		t.IsTTY = fi.Mode()&os.ModeCharDevice != 0
+	}
+
+	if t.IsTTY {
+		t.Width, t.Height = terminalSize(os.Stdout.Fd())
+	}
+	if v, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && v > 0 {
+		t.Width = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LINES")); err == nil && v > 0 {
+		t.Height = v
+	}
+
+	term := os.Getenv("TERM")
+	_, noColor := os.LookupEnv("NO_COLOR")
+	switch {
+	case !t.IsTTY || noColor || term == "" || term == "dumb":
+		t.Colors = 0
+	case os.Getenv("COLORTERM") == "truecolor" || os.Getenv("COLORTERM") == "24bit":
+		t.Colors = 1 << 24
+	case strings.Contains(term, "256color"):
+		t.Colors = 256
+	default:
+		t.Colors = 16
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			t.Unicode = strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+			break
+		}
+	}
+	return t
+}
+
+// isTerminal returns true if the file is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	if term := os.Getenv("TERM"); term == "dumb" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// isColorTerminal returns true if the file is a terminal that supports colors
+// and colors are not disabled with the NO_COLOR environment variable.
+func isColorTerminal(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	if os.Getenv("TERM") == "" {
+		return false
+	}
+	return isTerminal(f)
+}
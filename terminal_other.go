@@ -0,0 +1,11 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package subcmd
+
+// terminalSize returns zeros because terminal size cannot be determined on
+// this platform.
+func terminalSize(fd uintptr) (width, height int) {
+	return 0, 0
+}
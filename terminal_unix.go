@@ -0,0 +1,22 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package subcmd
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalSize returns the terminal width and height for the file descriptor
+// or zeros when they cannot be determined.
+func terminalSize(fd uintptr) (width, height int) {
+	var ws struct {
+		Row, Col, Xpixel, Ypixel uint16
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0, 0
+	}
+	return int(ws.Col), int(ws.Row)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import "strings"
+
+// Token describes a command-line argument as seen by the argument parser.
+type Token struct {
+	// Arg is the command-line argument.
+	Arg string
+
+	// IsFlag is true when the argument starts with a '-' or '--' prefix and
+	// appears before the "--" argument.
+	IsFlag bool
+
+	// IsTerminator is true for the first "--" argument, which ends the flags.
+	IsTerminator bool
+
+	// BadSyntax is true for flag arguments with an invalid syntax.
+	BadSyntax bool
+
+	// Name, Value and HasValue hold the parts of a flag argument. HasValue is
+	// true only when the value is given with the `-name=value` syntax.
+	Name     string
+	Value    string
+	HasValue bool
+}
+
+// Tokenize splits the command-line arguments into tokens the same way as the
+// Run function does. Since tokens are not matched against the flag
+// definitions, values of non-boolean flags given as separate arguments are
+// tokenized as plain arguments.
+func Tokenize(args []string) []Token {
+	var tokens []Token
+	terminated := false
+	for _, s := range args {
+		t := Token{Arg: s}
+		switch {
+		case terminated:
+		case s == "--":
+			t.IsTerminator, terminated = true, true
+		case len(s) >= 2 && s[0] == '-':
+			var ok bool
+			t.IsFlag = true
+			t.Name, t.Value, t.HasValue, ok = splitFlag(s)
+			t.BadSyntax = !ok
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens
+}
+
+// splitFlag removes the '-' or '--' prefix and the '=...' suffix from a flag
+// argument and returns the flag name and value. It returns false if the flag
+// syntax is invalid.
+func splitFlag(s string) (name, value string, hasValue, ok bool) {
+	name = s[1:]
+	if s[1] == '-' {
+		name = s[2:]
+	}
+	if len(name) == 0 || name[0] == '-' || name[0] == '=' {
+		return "", "", false, false
+	}
+	if pos := strings.Index(name, "="); pos >= 0 {
+		return name[:pos], name[pos+1:], true, true
+	}
+	return name, "", false, true
+}
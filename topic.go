@@ -0,0 +1,61 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	helpTopicsMu sync.Mutex
+	helpTopics   = make(map[string]string)
+)
+
+// RegisterHelpTopic adds a documentation page that is not a command, like
+// "environment" or "config-file", which is displayed by the builtin
+// "help <topic>" command. Topics are listed in the top-level help output with
+// the first line of the text as the summary. Top-level commands with the same
+// name take precedence over the topics.
+func RegisterHelpTopic(name, text string) {
+	helpTopicsMu.Lock()
+	defer helpTopicsMu.Unlock()
+	helpTopics[name] = text
+}
+
+// lookupHelpTopic returns the text for a registered help topic.
+func lookupHelpTopic(name string) (string, bool) {
+	helpTopicsMu.Lock()
+	defer helpTopicsMu.Unlock()
+	text, ok := helpTopics[name]
+	return text, ok
+}
+
+// getHelpTopics returns all registered help topics with their summaries in
+// the sorted order.
+func getHelpTopics() []HelpCommand {
+	helpTopicsMu.Lock()
+	defer helpTopicsMu.Unlock()
+
+	var topics []HelpCommand
+	for name, text := range helpTopics {
+		topics = append(topics, HelpCommand{Name: name, Synopsis: getFirstLine(text)})
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		return topics[i].Name < topics[j].Name
+	})
+	return topics
+}
+
+// printHelpTopic prints the help topic text through the pager when necessary.
+func (cg *cmdGroup) printHelpTopic(ctx context.Context, name string) error {
+	text, ok := lookupHelpTopic(name)
+	if !ok {
+		return errors.New(msg(MsgCommandNotDefined, name))
+	}
+	style := cg.getHelpStyle(ctx)
+	return cg.writePaged(ctx, []byte(wrapText(strings.TrimSpace(text), style.width)+"\n"))
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Validate checks the command tree for problems that would otherwise only be
+// found at run time, like moved command stubs that are not registered at their
+// old paths or forward to each other in a loop, or related commands that are
+// not defined. It is meant to be called from
+// tests.
+func Validate(cmds []Command) error {
+	moved := make(map[string]string)
+	defined := make(map[string]bool)
+	seeAlso := make(map[string][]string)
+	var paths, related []string
+	var misplaced error
+	walkTree(new(NodeCache), nil, cmds, func(path []string, c Command) bool {
+		p := strings.Join(path, " ")
+		defined[p] = true
+		if targets := getSeeAlso(c); len(targets) > 0 {
+			seeAlso[p] = targets
+			related = append(related, p)
+		}
+		if mc, ok := c.(*movedCmd); ok {
+			if oldPath := strings.Join(mc.oldPath, " "); oldPath != p && misplaced == nil {
+				misplaced = errors.New(msg(MsgMovedPathMismatch, oldPath, p))
+			}
+			moved[p] = strings.Join(mc.newPath, " ")
+			paths = append(paths, p)
+		}
+		return true
+	})
+
+	if misplaced != nil {
+		return misplaced
+	}
+
+	for _, p := range paths {
+		chain := []string{p}
+		for next, ok := moved[p]; ok; next, ok = moved[next] {
+			if slices.Contains(chain, next) {
+				return &CycleError{Chain: append(chain, next)}
+			}
+			chain = append(chain, next)
+		}
+	}
+
+	for _, p := range related {
+		for _, target := range seeAlso[p] {
+			if !defined[target] {
+				return errors.New(msg(MsgSeeAlsoNotDefined, p, target))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+)
+
+type versionCmd struct {
+	custom func() string
+}
+
+// Version creates a "version" command that prints the module version, VCS
+// revision and commit time of the program from the build information embedded
+// in the binary by the Go toolchain. When the custom function is not nil, it's
+// result is printed instead of the module version, which is useful for the
+// version strings set with the linker flags. A top-level version command also
+// enables the `-version` flag, which runs the version command.
+func Version(custom func() string) Command {
+	return &versionCmd{custom: custom}
+}
+
+func (c *versionCmd) Command() (*flag.FlagSet, MainFunc) {
+	return flag.NewFlagSet("version", flag.ContinueOnError), c.run
+}
+
+func (c *versionCmd) CommandHelp() string {
+	return `Prints the program version.
+
+Prints the program version with the VCS revision and commit time recorded in
+the build information of the binary.
+`
+}
+
+func (c *versionCmd) run(ctx context.Context, args []string) error {
+	return c.writeVersion(getStdout(ctx))
+}
+
+func (c *versionCmd) writeVersion(w io.Writer) error {
+	version := msg(MsgVersionUnknown)
+	info, ok := debug.ReadBuildInfo()
+	if ok && len(info.Main.Version) > 0 {
+		version = info.Main.Version
+	}
+	if c.custom != nil {
+		version = c.custom()
+	}
+	fmt.Fprintln(w, msg(MsgLabelVersion, strings.TrimSpace(version)))
+	if !ok {
+		return nil
+	}
+
+	settings := make(map[string]string)
+	for _, s := range info.Settings {
+		settings[s.Key] = s.Value
+	}
+	if revision := settings["vcs.revision"]; len(revision) > 0 {
+		if settings["vcs.modified"] == "true" {
+			revision = msg(MsgVersionModified, revision)
+		}
+		fmt.Fprintln(w, msg(MsgLabelRevision, revision))
+	}
+	if when := settings["vcs.time"]; len(when) > 0 {
+		fmt.Fprintln(w, msg(MsgLabelTime, when))
+	}
+	fmt.Fprintln(w, msg(MsgLabelGo, info.GoVersion))
+	return nil
+}
+
+// lookupVersion returns the top-level version command, if any.
+func (cg *cmdGroup) lookupVersion() (*versionCmd, bool) {
+	for _, c := range cg.subcmds {
+		if v, ok := unwrapHidden(c).(*versionCmd); ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/bvkgo/subcmd/cmdctx"
+)
+
+// warnKey holds the warning counts of the invocation.
+var warnKey = cmdctx.NewKey[*warnState]("warnings")
+
+type warnState struct {
+	count    atomic.Int64
+	asErrors atomic.Bool
+}
+
+func withWarnings(ctx context.Context, asErrors bool) context.Context {
+	ws, ok := warnKey.Value(ctx)
+	if !ok {
+		ws = new(warnState)
+		ctx = warnKey.With(ctx, ws)
+	}
+	if asErrors {
+		ws.asErrors.Store(true)
+	}
+	return ctx
+}
+
+// Warn prints a warning message to the standard error with a consistent
+// prefix, which is colored when the standard error is a terminal. Warnings are
+// counted per invocation, so that a command that reported warnings fails when
+// the `-warnings-as-errors` flag is given.
+func Warn(ctx context.Context, format string, args ...any) {
+	if ws, ok := warnKey.Value(ctx); ok {
+		ws.count.Add(1)
+	}
+
+	prefix := msg(MsgWarningPrefix)
+	w := getStderr(ctx)
+	if f, ok := w.(*os.File); ok && isColorTerminal(f) {
+		prefix = ansiYellow + prefix + ansiReset
+	}
+	fmt.Fprintf(w, "%s%s\n", prefix, fmt.Sprintf(format, args...))
+}
+
+// checkWarnings returns an error if warnings were reported and they must be
+// treated as errors.
+func checkWarnings(ctx context.Context) error {
+	ws, ok := warnKey.Value(ctx)
+	if !ok || !ws.asErrors.Load() {
+		return nil
+	}
+	if n := int(ws.count.Load()); n > 0 {
+		return errors.New(msgn(MsgWarningsAsErrors, n, n))
+	}
+	return nil
+}
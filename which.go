@@ -0,0 +1,73 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// getSource describes where a command is defined, like "core" or
+// "plugin foo", for the "which" command.
+func getSource(c Command) string {
+	if origin := getOrigin(c); len(origin) > 0 {
+		return "plugin " + origin
+	}
+	if _, ok := c.(*remoteCmd); ok {
+		return "remote"
+	}
+	return "core"
+}
+
+// printWhich prints the command that would run for the arguments, after the
+// shortcut expansion and following the moved command stubs, with where the
+// command is defined and the residual arguments, without running it.
+func (cg *cmdGroup) printWhich(ctx context.Context, w io.Writer, args []string) error {
+	// resolving the arguments must not change the flags
+	restore := cg.saveFlagValues()
+	defer restore()
+
+	var moves []string
+	for {
+		next := cg.rerun()
+		cmdseq, rest, err := next.resolve(ctx, args)
+		if err != nil {
+			return err
+		}
+		path := getPath(cmdseq)
+		prog := path[0]
+		if len(next.alias) > 0 {
+			fmt.Fprintln(w, msg(MsgLabelShortcut, next.alias))
+		}
+
+		last := cmdseq[len(cmdseq)-1]
+		if mc, ok := last.cmd.(*movedCmd); ok {
+			oldPath := strings.Join(mc.oldPath, " ")
+			if slices.Contains(moves, oldPath) {
+				return &CycleError{Chain: append(moves, oldPath)}
+			}
+			moves = append(moves, oldPath)
+			fmt.Fprintln(w, msg(MsgLabelMoved, prog+" "+oldPath, prog+" "+strings.Join(mc.newPath, " ")))
+			args = append(slices.Clip(mc.newPath), rest...)
+			continue
+		}
+
+		source := getSource(last.cmd)
+		if len(cmdseq) == 1 && len(next.specialCmd) > 0 {
+			path, source = append(path, next.specialCmd), msg(MsgSourceBuiltin)
+		}
+		fmt.Fprintln(w, msg(MsgLabelCommand, strings.Join(path, " ")))
+		fmt.Fprintln(w, msg(MsgLabelSource, source))
+
+		var quoted []string
+		for _, arg := range rest {
+			quoted = append(quoted, strconv.Quote(arg))
+		}
+		fmt.Fprintln(w, msg(MsgLabelArgs, strings.Join(quoted, " ")))
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// WizardStep describes a single prompt of an interactive wizard, whose answer
+// is the value for a flag.
+type WizardStep struct {
+	// Flag is the name of the flag that takes the answer.
+	Flag string
+
+	// Prompt is the question displayed to the user.
+	Prompt string
+
+	// Optional steps can be skipped, which leaves the flag with it's current
+	// value.
+	Optional bool
+
+	// Validate, when non-nil, checks the answer before it is accepted.
+	Validate func(answer string) error
+}
+
+// Wizard prompts for the flag values one step at a time, so that complex
+// commands can be interactive. Current flag values are used as the default
+// answers. Answering "<" goes back to the previous step and "-" skips an
+// optional step. Finally, the equivalent non-interactive command line is
+// displayed for a confirmation, which teaches the user the scriptable form of
+// the command. Answers are set on the flags only when they are confirmed, after
+// which the equivalent command is also echoed with the EchoEquivalent
+// function.
+//
+//	if err := subcmd.Wizard(ctx, fset, steps...); err != nil {
+//		return err
+//	}
+func Wizard(ctx context.Context, fset *flag.FlagSet, steps ...WizardStep) error {
+	return runWizard(ctx, os.Stdin, getStdout(ctx), fset, steps)
+}
+
+func runWizard(ctx context.Context, r io.Reader, w io.Writer, fset *flag.FlagSet, steps []WizardStep) error {
+	in := bufio.NewScanner(r)
+	readLine := func() (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if !in.Scan() {
+			if err := in.Err(); err != nil {
+				return "", err
+			}
+			return "", io.ErrUnexpectedEOF
+		}
+		return strings.TrimSpace(in.Text()), nil
+	}
+
+	answers := make([]*string, len(steps))
+	for i := 0; i < len(steps); {
+		step := steps[i]
+		f := fset.Lookup(step.Flag)
+		if f == nil {
+			return fmt.Errorf("%s: %w", msg(MsgFlagNotDefined, step.Flag), os.ErrInvalid)
+		}
+
+		value := f.Value.String()
+		if answers[i] != nil {
+			value = *answers[i]
+		}
+		if len(value) > 0 {
+			fmt.Fprintf(w, "%s [%s]: ", step.Prompt, value)
+		} else {
+			fmt.Fprintf(w, "%s: ", step.Prompt)
+		}
+
+		answer, err := readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case answer == "<":
+			if i > 0 {
+				i--
+			}
+			continue
+		case answer == "-" && step.Optional:
+			answers[i] = nil
+			i++
+			continue
+		case len(answer) == 0:
+			answer = value
+		}
+
+		if step.Validate != nil {
+			if err := step.Validate(answer); err != nil {
+				fmt.Fprintf(w, "%s\n", err)
+				continue
+			}
+		}
+		answers[i] = &answer
+		i++
+	}
+
+	words := getCommandPath(ctx)
+	for i, step := range steps {
+		if answers[i] != nil {
+			words = append(words, "-"+step.Flag+"="+shellQuote(*answers[i]))
+		}
+	}
+	fmt.Fprintf(w, "\n%s\n\t%s\n", msg(MsgWizardEquivalent), strings.Join(words, " "))
+	fmt.Fprintf(w, "%s ", msg(MsgWizardConfirm))
+	answer, err := readLine()
+	if err != nil {
+		return err
+	}
+	if a := strings.ToLower(answer); len(a) > 0 && a != "y" && a != "yes" {
+		return errors.New(msg(MsgWizardCanceled))
+	}
+
+	for i, step := range steps {
+		if answers[i] == nil {
+			continue
+		}
+		if err := fset.Set(step.Flag, *answers[i]); err != nil {
+			return fmt.Errorf("%s: %w", msg(MsgInvalidFlagValue, *answers[i], step.Flag), err)
+		}
+	}
+	EchoEquivalent(ctx, fset)
+	return nil
+}
+
+// shellQuote quotes the string for the POSIX shells when it has any special
+// characters.
+func shellQuote(s string) string {
+	if len(s) > 0 && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_.,:/@%+=") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
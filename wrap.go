@@ -0,0 +1,52 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// wrapText breaks the lines longer than width characters at the word
+// boundaries. Existing line breaks are kept as they are, so that hand
+// formatted lists and examples are not reflowed, and continuation lines keep
+// the leading whitespace of the original line. Text is unchanged when width
+// is not positive.
+func wrapText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+
+	lines := strings.Split(text, "\n")
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		if utf8.RuneCountInString(line) <= width {
+			sb.WriteString(line)
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		n := 0
+		for j, word := range strings.Fields(line) {
+			wlen := utf8.RuneCountInString(word)
+			switch {
+			case j == 0:
+				sb.WriteString(indent)
+				n = utf8.RuneCountInString(indent)
+			case n+1+wlen > width:
+				sb.WriteByte('\n')
+				sb.WriteString(indent)
+				n = utf8.RuneCountInString(indent)
+			default:
+				sb.WriteByte(' ')
+				n++
+			}
+			sb.WriteString(word)
+			n += wlen
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2023 BVK Chaitanya
+
+package subcmd
+
+import "testing"
+
+func TestWrapText(t *testing.T) {
+	for _, test := range []struct {
+		text  string
+		width int
+		want  string
+	}{
+		{"one two three", 0, "one two three"},
+		{"one two three", 20, "one two three"},
+		{"one two three four", 9, "one two\nthree\nfour"},
+		{"  - one two three\nshort", 12, "  - one two\n  three\nshort"},
+		{"unbreakablewords here", 5, "unbreakablewords\nhere"},
+	} {
+		if got := wrapText(test.text, test.width); got != test.want {
+			t.Errorf("wrapText(%q, %d): want %q, got %q", test.text, test.width, test.want, got)
+		}
+	}
+}